@@ -2,8 +2,14 @@ package aws
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/aws-sdk-go/aws"
@@ -25,6 +31,36 @@ type Config struct {
 	CredentialsFileProfile string
 	Region                 string
 	Provider               aws.CredentialsProvider
+
+	// Route53Endpoint, if set, is the URL that all Route53 requests are
+	// sent to instead of the default endpoint.
+	Route53Endpoint string
+
+	// SigningRegion, if set, overrides the region that Route53 requests
+	// are signed for, e.g. for a mock endpoint that expects a region
+	// other than us-east-1. IAM can't be overridden, see IAMRegion.
+	SigningRegion string
+
+	// RequestTimeout and DialTimeout, if non-zero, limit how long a single
+	// request to AWS and the connection for it may take, so that a hung
+	// endpoint can't stall Terraform indefinitely.
+	RequestTimeout time.Duration
+	DialTimeout    time.Duration
+
+	// StrictServiceRegions makes it an error, rather than a warning, to
+	// configure a region that a service Terraform connects to isn't
+	// offered in. See serviceUnavailableRegions.
+	StrictServiceRegions bool
+
+	// SkipRegionValidation allows regions that ValidateRegion doesn't know
+	// about, such as brand new regions or mock endpoints.
+	SkipRegionValidation bool
+
+	// EC2MetadataEndpoint, if set, is the address of the EC2 instance
+	// metadata service, e.g. a metadata proxy, used to discover the region
+	// and to get instance profile credentials. It falls back to
+	// AWS_EC2_METADATA_SERVICE_ENDPOINT.
+	EC2MetadataEndpoint string
 }
 
 type AWSClient struct {
@@ -34,11 +70,39 @@ type AWSClient struct {
 	s3conn          *s3.S3
 	r53conn         *route53.Route53
 	region          string
+	globalRegion    string
 	rdsconn         *rds.RDS
 	iamconn         *iam.IAM
+
+	// credsProvider and httpClient are kept so that connections to other
+	// regions can be made when needed, e.g. for S3 buckets outside of
+	// region.
+	credsProvider aws.CredentialsProvider
+	httpClient    *http.Client
+
+	s3lock          sync.Mutex
+	s3BucketRegions map[string]string
+	s3conns         map[string]*s3.S3
+
+	// The identity of the caller is looked up on first use and cached
+	// until the credentials change, see AccountID and UserID.
+	// callerIdentityFunc does the lookup and defaults to iamCallerIdentity.
+	callerIdentityLock   sync.Mutex
+	callerIdentityFunc   func() (accountID, userID string, err error)
+	callerIdentityLoaded bool
+	callerIdentityKey    string
+	accountID            string
+	userID               string
 }
 
 func (c *Config) loadAndValidate(providerCode string) (interface{}, error) {
+	// Credentials are checked against what was configured explicitly, before
+	// the deprecated environment variables are mixed in.
+	if err := c.ValidateCredentials(providerCode); err != nil {
+		return nil, err
+	}
+	providerCode = c.credsProviderCode(providerCode)
+
 	c.tryLoadingDeprecatedEnvVars()
 	credsProvider, err := c.getCredsProvider(providerCode)
 	if err != nil {
@@ -67,10 +131,85 @@ func (c *Config) tryLoadingDeprecatedEnvVars() {
 	}
 }
 
+// ValidateCredentials returns an error if the credential settings conflict
+// with each other or with providerCode, since only one of them would
+// actually be used.
+func (c *Config) ValidateCredentials(providerCode string) error {
+	var errs []error
+
+	keys := c.AccessKey != "" || c.SecretKey != "" || c.Token != ""
+	file := c.CredentialsFilePath != "" || c.CredentialsFileProfile != ""
+
+	switch providerCode {
+	case "", "detect":
+		if keys && file {
+			errs = append(errs, fmt.Errorf(
+				"access_key, secret_key and security_token can't be combined "+
+					"with credentials_file_path or credentials_file_profile"))
+		}
+	case "static":
+		if c.AccessKey == "" || c.SecretKey == "" {
+			errs = append(errs, fmt.Errorf(
+				"credentials_provider \"static\" requires access_key and secret_key"))
+		}
+		if file {
+			errs = append(errs, fmt.Errorf(
+				"credentials_file_path and credentials_file_profile are only "+
+					"used with credentials_provider \"file\""))
+		}
+	case "iam", "env":
+		if keys {
+			errs = append(errs, fmt.Errorf(
+				"access_key, secret_key and security_token are only used with "+
+					"credentials_provider \"static\" or \"detect\", not %q",
+				providerCode))
+		}
+		if file {
+			errs = append(errs, fmt.Errorf(
+				"credentials_file_path and credentials_file_profile are only "+
+					"used with credentials_provider \"file\""))
+		}
+	case "file":
+		if keys {
+			errs = append(errs, fmt.Errorf(
+				"access_key, secret_key and security_token are only used with "+
+					"credentials_provider \"static\" or \"detect\", not \"file\""))
+		}
+	default:
+		errs = append(errs, fmt.Errorf(
+			"Unknown credentials_provider %q, must be one of "+
+				"detect, static, iam, env or file", providerCode))
+	}
+
+	if len(errs) > 0 {
+		return &multierror.Error{Errors: errs}
+	}
+
+	return nil
+}
+
+// credsProviderCode returns the credentials provider to use for
+// providerCode. Configuring a credentials file implies the file provider
+// when none was chosen.
+func (c *Config) credsProviderCode(providerCode string) string {
+	if providerCode != "" && providerCode != "detect" {
+		return providerCode
+	}
+
+	if c.CredentialsFilePath != "" || c.CredentialsFileProfile != "" {
+		return "file"
+	}
+
+	return providerCode
+}
+
 func (c *Config) getCredsProvider(providerCode string) (aws.CredentialsProvider, error) {
 	if providerCode == "static" {
 		return aws.Creds(c.AccessKey, c.SecretKey, c.Token), nil
 	} else if providerCode == "iam" {
+		if c.metadataEndpoint() != "" {
+			return newMetadataCreds(c.metadataURL()), nil
+		}
 		return aws.IAMCreds(), nil
 	} else if providerCode == "env" {
 		return aws.EnvCreds()
@@ -82,6 +221,25 @@ func (c *Config) getCredsProvider(providerCode string) (aws.CredentialsProvider,
 		return aws.ProfileCreds(
 			c.CredentialsFilePath, c.CredentialsFileProfile, expiry)
 	}
+
+	// aws.DetectCreds falls back to the instance profile at the default
+	// metadata address, so with another endpoint the fallbacks are made
+	// here: the environment, then the default credentials file, then the
+	// instance profile.
+	if c.AccessKey == "" && c.metadataEndpoint() != "" {
+		if creds, err := aws.EnvCreds(); err == nil {
+			return creds, nil
+		}
+		// ProfileCreds doesn't read the file, so it succeeds even without
+		// one. Check it has credentials, as aws.DetectCreds does.
+		if creds, err := aws.ProfileCreds("", "", 10*time.Minute); err == nil {
+			if _, err := creds.Credentials(); err == nil {
+				return creds, nil
+			}
+		}
+		return newMetadataCreds(c.metadataURL()), nil
+	}
+
 	return aws.DetectCreds(c.AccessKey, c.SecretKey, c.Token), nil
 }
 
@@ -90,47 +248,379 @@ func (c *Config) Client() (interface{}, error) {
 	var client AWSClient
 
 	// Get the auth and region. This can fail if keys/regions were not
-	// specified and we're attempting to use the environment.
+	// specified and we're attempting to use the environment. Everything
+	// is checked before any connection is made, so that all problems with
+	// the configuration are reported at once.
 	var errs []error
 
 	log.Println("[INFO] Building AWS region structure")
+	c.resolveRegion()
 	err := c.ValidateRegion()
 	if err != nil {
 		errs = append(errs, err)
 	}
 
-	if len(errs) == 0 {
-		// store AWS region in client struct, for region specific operations such as
-		// bucket storage in S3
-		client.region = c.Region
-		credsProvider := c.Provider
+	for _, err := range c.unavailableServices() {
+		if c.StrictServiceRegions {
+			errs = append(errs, err)
+		} else {
+			log.Printf("[WARN] %s", err)
+		}
+	}
 
-		log.Println("[INFO] Initializing ELB connection")
-		client.elbconn = elb.New(credsProvider, c.Region, nil)
-		log.Println("[INFO] Initializing AutoScaling connection")
-		client.autoscalingconn = autoscaling.New(credsProvider, c.Region, nil)
-		log.Println("[INFO] Initializing S3 connection")
-		client.s3conn = s3.New(credsProvider, c.Region, nil)
-		log.Println("[INFO] Initializing RDS connection")
-		client.rdsconn = rds.New(credsProvider, c.Region, nil)
+	if c.Provider == nil {
+		errs = append(errs, fmt.Errorf("No AWS credentials provider configured"))
+	}
 
-		// aws-sdk-go uses v4 for signing requests, which requires all global
-		// endpoints to use 'us-east-1'.
-		// See http://docs.aws.amazon.com/general/latest/gr/sigv4_changes.html
-		log.Println("[INFO] Initializing Route53 connection")
-		client.r53conn = route53.New(credsProvider, "us-east-1", nil)
-		log.Println("[INFO] Initializing EC2 Connection")
-		client.ec2conn = ec2.New(credsProvider, c.Region, nil)
-		client.iamconn = iam.New(credsProvider, c.Region, nil)
+	if c.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("Request timeout can't be negative: %s", c.RequestTimeout))
+	}
+	if c.DialTimeout < 0 {
+		errs = append(errs, fmt.Errorf("Dial timeout can't be negative: %s", c.DialTimeout))
+	}
+
+	httpClient := c.httpClient()
+	r53client, err := endpointClient(c.Route53Endpoint, httpClient)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Error configuring Route53: %s", err))
 	}
 
 	if len(errs) > 0 {
 		return nil, &multierror.Error{Errors: errs}
 	}
 
+	// store AWS region in client struct, for region specific operations such as
+	// bucket storage in S3
+	client.region = c.Region
+	client.globalRegion = c.globalRegion()
+	credsProvider := c.Provider
+	client.credsProvider = credsProvider
+	client.httpClient = httpClient
+
+	log.Println("[INFO] Initializing ELB connection")
+	client.elbconn = elb.New(credsProvider, c.Region, httpClient)
+	log.Println("[INFO] Initializing AutoScaling connection")
+	client.autoscalingconn = autoscaling.New(credsProvider, c.Region, httpClient)
+	log.Println("[INFO] Initializing S3 connection")
+	client.s3conn = s3.New(credsProvider, c.Region, httpClient)
+	log.Println("[INFO] Initializing RDS connection")
+	client.rdsconn = rds.New(credsProvider, c.Region, httpClient)
+
+	// aws-sdk-go uses v4 for signing requests, which requires all global
+	// endpoints to use 'us-east-1'. This can be overridden with
+	// SigningRegion.
+	// See http://docs.aws.amazon.com/general/latest/gr/sigv4_changes.html
+	log.Println("[INFO] Initializing Route53 connection")
+	client.r53conn = route53.New(credsProvider, client.globalRegion, r53client)
+	log.Println("[INFO] Initializing EC2 Connection")
+	client.ec2conn = ec2.New(credsProvider, c.Region, httpClient)
+	client.iamconn = iam.New(credsProvider, c.Region, httpClient)
+
 	return &client, nil
 }
 
+// EC2Region returns the region that the EC2 connection is made to.
+func (c *AWSClient) EC2Region() string {
+	return c.region
+}
+
+// ELBRegion returns the region that the ELB connection is made to.
+func (c *AWSClient) ELBRegion() string {
+	return c.region
+}
+
+// AutoScalingRegion returns the region that the AutoScaling connection is
+// made to.
+func (c *AWSClient) AutoScalingRegion() string {
+	return c.region
+}
+
+// S3Region returns the region that the default S3 connection is made to.
+// Buckets in other regions get their own connection, see S3ForBucket.
+func (c *AWSClient) S3Region() string {
+	return c.region
+}
+
+// RDSRegion returns the region that the RDS connection is made to.
+func (c *AWSClient) RDSRegion() string {
+	return c.region
+}
+
+// IAMRegion returns the region that IAM requests are signed for. IAM is a
+// global service: aws-sdk-go sends it to the endpoint and signing region of
+// the configured region's partition, whatever region it is given, so
+// SigningRegion has no effect on it.
+func (c *AWSClient) IAMRegion() string {
+	return partitionRegion(c.region)
+}
+
+// Route53Region returns the region that Route53 requests are signed for.
+// Route53 is a global service, so this is us-east-1 rather than the
+// configured region.
+func (c *AWSClient) Route53Region() string {
+	return c.globalRegion
+}
+
+// AccountID returns the ID of the AWS account that the credentials belong
+// to. It is looked up on first use and cached until the credentials change.
+func (c *AWSClient) AccountID() (string, error) {
+	accountID, _, err := c.loadCallerIdentity()
+	return accountID, err
+}
+
+// UserID returns the unique ID of the IAM user that the credentials belong
+// to. It is looked up on first use and cached until the credentials change.
+func (c *AWSClient) UserID() (string, error) {
+	_, userID, err := c.loadCallerIdentity()
+	return userID, err
+}
+
+// loadCallerIdentity returns the cached identity of the caller, looking it
+// up if there is none yet or the credentials were refreshed with another
+// access key since. Failed lookups aren't cached, so that a transient error
+// is retried on the next call.
+func (c *AWSClient) loadCallerIdentity() (string, string, error) {
+	key := c.accessKeyID()
+
+	c.callerIdentityLock.Lock()
+	if c.callerIdentityLoaded && c.callerIdentityKey == key {
+		defer c.callerIdentityLock.Unlock()
+		return c.accountID, c.userID, nil
+	}
+	f := c.callerIdentityFunc
+	c.callerIdentityLock.Unlock()
+
+	if f == nil {
+		f = c.iamCallerIdentity
+	}
+
+	log.Println("[INFO] Looking up the caller's AWS account")
+	accountID, userID, err := f()
+	if err != nil {
+		return "", "", err
+	}
+
+	c.callerIdentityLock.Lock()
+	defer c.callerIdentityLock.Unlock()
+	c.callerIdentityLoaded = true
+	c.callerIdentityKey = key
+	c.accountID = accountID
+	c.userID = userID
+
+	return accountID, userID, nil
+}
+
+// accessKeyID returns the access key ID of the current credentials, or an
+// empty string if there are none.
+func (c *AWSClient) accessKeyID() string {
+	if c.credsProvider == nil {
+		return ""
+	}
+
+	creds, err := c.credsProvider.Credentials()
+	if err != nil || creds == nil {
+		return ""
+	}
+
+	return creds.AccessKeyID
+}
+
+// iamCallerIdentity looks up the account and user ID of the caller from
+// the IAM user the credentials belong to.
+func (c *AWSClient) iamCallerIdentity() (string, string, error) {
+	// A zero value GetUserRequest{} defers to the currently logged in user
+	resp, err := c.iamconn.GetUser(&iam.GetUserRequest{})
+	if err != nil {
+		return "", "", fmt.Errorf("Error looking up the current IAM user: %s", err)
+	}
+
+	user := resp.User
+	if user == nil || user.ARN == nil || user.UserID == nil {
+		return "", "", fmt.Errorf("IAM returned no details of the current user")
+	}
+
+	accountID, err := accountIDFromARN(*user.ARN)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accountID, *user.UserID, nil
+}
+
+// accountIDFromARN returns the account ID field of an ARN such as
+// "arn:aws:iam::123456789012:user/foo".
+func accountIDFromARN(arn string) (string, error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[4] == "" {
+		return "", fmt.Errorf("Unable to parse account ID from ARN: %s", arn)
+	}
+
+	return parts[4], nil
+}
+
+// regionEnvVars are the environment variables that the region is read
+// from, in order of precedence, when it isn't configured explicitly.
+var regionEnvVars = []string{"AWS_REGION", "AWS_DEFAULT_REGION"}
+
+// resolveRegion falls back to the region from the environment if one
+// wasn't configured explicitly.
+func (c *Config) resolveRegion() {
+	if c.Region != "" {
+		return
+	}
+
+	for _, k := range regionEnvVars {
+		if v := os.Getenv(k); v != "" {
+			log.Printf("[INFO] Using region %s from %s", v, k)
+			c.Region = v
+			return
+		}
+	}
+
+	if v := metadataRegion(c.metadataURL()); v != "" {
+		log.Printf("[INFO] Using region %s from EC2 instance metadata", v)
+		c.Region = v
+	}
+}
+
+// ec2MetadataEndpointEnvVar is the environment variable the AWS tools use
+// to point at a different instance metadata service.
+const ec2MetadataEndpointEnvVar = "AWS_EC2_METADATA_SERVICE_ENDPOINT"
+
+// metadataEndpoint returns the address of the instance metadata service
+// if one was configured, or an empty string to use the default.
+func (c *Config) metadataEndpoint() string {
+	if c.EC2MetadataEndpoint != "" {
+		return c.EC2MetadataEndpoint
+	}
+
+	return os.Getenv(ec2MetadataEndpointEnvVar)
+}
+
+// metadataURL returns the base URL of the instance metadata to read, which
+// is ec2MetadataURL unless another endpoint was configured.
+func (c *Config) metadataURL() string {
+	endpoint := c.metadataEndpoint()
+	if endpoint == "" {
+		return ec2MetadataURL
+	}
+
+	return strings.TrimSuffix(endpoint, "/") + "/latest/meta-data/"
+}
+
+// ec2MetadataURL is the base URL of the EC2 instance metadata service. It's
+// a variable so tests can point it at a mock, and setting it to the empty
+// string disables the lookup.
+var ec2MetadataURL = "http://169.254.169.254/latest/meta-data/"
+
+// ec2MetadataTimeout bounds the metadata lookup so that runs outside of EC2
+// aren't held up waiting for an address that will never answer.
+const ec2MetadataTimeout = 1 * time.Second
+
+var (
+	metadataRegionLock  sync.Mutex
+	metadataRegionCache = make(map[string]string)
+)
+
+// metadataRegion returns the region of the EC2 instance Terraform is running
+// on, derived from its availability zone, or an empty string if the metadata
+// service can't be reached. The result, including a failed lookup, is cached
+// per URL so the service is asked at most once.
+func metadataRegion(base string) string {
+	if base == "" {
+		return ""
+	}
+
+	metadataRegionLock.Lock()
+	defer metadataRegionLock.Unlock()
+
+	if v, ok := metadataRegionCache[base]; ok {
+		return v
+	}
+
+	region, err := fetchMetadataRegion(base)
+	if err != nil {
+		log.Printf("[DEBUG] Couldn't read region from EC2 instance metadata: %s", err)
+	}
+	metadataRegionCache[base] = region
+	return region
+}
+
+func fetchMetadataRegion(base string) (string, error) {
+	client := &http.Client{Timeout: ec2MetadataTimeout}
+	resp, err := client.Get(strings.TrimSuffix(base, "/") + "/placement/availability-zone")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// The region is the availability zone without its trailing letter,
+	// e.g. "us-west-2b" is in "us-west-2".
+	az := strings.TrimSpace(string(body))
+	if len(az) < 2 {
+		return "", fmt.Errorf("invalid availability zone: %q", az)
+	}
+	return az[:len(az)-1], nil
+}
+
+// serviceUnavailableRegions maps the services that Terraform connects to
+// onto the regions they aren't offered in. Services that are offered in
+// every region are left out.
+var serviceUnavailableRegions = map[string][]string{
+	"Route53": []string{"cn-north-1", "us-gov-west-1"},
+}
+
+// unavailableServices returns an error for every service that isn't offered
+// in the configured region, so that it can be reported up front rather than
+// on first use.
+func (c *Config) unavailableServices() []error {
+	var errs []error
+	for service, regions := range serviceUnavailableRegions {
+		for _, r := range regions {
+			if r == c.Region {
+				errs = append(errs, fmt.Errorf(
+					"%s is not available in region %s; resources that use it will fail",
+					service, c.Region))
+			}
+		}
+	}
+
+	return errs
+}
+
+// globalRegion returns the region that Route53 requests are signed for.
+// Unless SigningRegion is set, this is always us-east-1: Route53 isn't
+// offered outside the standard partition, see serviceUnavailableRegions.
+func (c *Config) globalRegion() string {
+	if c.SigningRegion != "" {
+		return c.SigningRegion
+	}
+
+	return "us-east-1"
+}
+
+// partitionRegion returns the canonical region of the partition that the
+// given region belongs to, which IAM requests are signed for.
+func partitionRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "cn-north-1"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "us-gov-west-1"
+	default:
+		return "us-east-1"
+	}
+}
+
 // IsValidRegion returns true if the configured region is a valid AWS
 // region and false if it's not
 func (c *Config) ValidateRegion() error {
@@ -138,6 +628,17 @@ func (c *Config) ValidateRegion() error {
 		"eu-central-1", "ap-southeast-1", "ap-southeast-2", "ap-northeast-1",
 		"sa-east-1", "cn-north-1", "us-gov-west-1"}
 
+	if c.Region == "" {
+		return fmt.Errorf(
+			"No region configured. Set region in the provider or one of %s",
+			strings.Join(regionEnvVars, ", "))
+	}
+
+	if c.SkipRegionValidation {
+		log.Printf("[INFO] Skipping validation of region %s", c.Region)
+		return nil
+	}
+
 	for _, valid := range regions {
 		if c.Region == valid {
 			return nil
@@ -145,3 +646,87 @@ func (c *Config) ValidateRegion() error {
 	}
 	return fmt.Errorf("Not a valid region: %s", c.Region)
 }
+
+// httpClient returns the *http.Client shared by all connections, which
+// applies RequestTimeout and DialTimeout. If neither is set, nil is
+// returned so the SDK uses its default client.
+func (c *Config) httpClient() *http.Client {
+	if c.RequestTimeout == 0 && c.DialTimeout == 0 {
+		return nil
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   c.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Client{
+		Timeout: c.RequestTimeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			Dial:                dialer.Dial,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// endpointClient returns an *http.Client that sends every request to the
+// given endpoint rather than the one aws-sdk-go computes for the service.
+// Requests are made with base, which may be nil for the default client.
+// If endpoint is empty, base is returned unchanged.
+func endpointClient(endpoint string, base *http.Client) (*http.Client, error) {
+	if endpoint == "" {
+		return base, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing endpoint %q: %s", endpoint, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf(
+			"Endpoint %q must include a scheme and host, e.g. https://%s",
+			endpoint, endpoint)
+	}
+
+	transport := http.DefaultTransport
+	var timeout time.Duration
+	if base != nil {
+		if base.Transport != nil {
+			transport = base.Transport
+		}
+		timeout = base.Timeout
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &endpointTransport{
+			Endpoint:  u,
+			Transport: transport,
+		},
+	}, nil
+}
+
+// endpointTransport is an http.RoundTripper that redirects requests to
+// a fixed endpoint. The Host header is left untouched so that the request
+// signature, which covers it, stays valid.
+type endpointTransport struct {
+	Endpoint  *url.URL
+	Transport http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request, so work on a copy.
+	r := new(http.Request)
+	*r = *req
+
+	u := *req.URL
+	u.Scheme = t.Endpoint.Scheme
+	u.Host = t.Endpoint.Host
+	r.URL = &u
+	if r.Host == "" {
+		r.Host = req.URL.Host
+	}
+
+	return t.Transport.RoundTrip(r)
+}
@@ -0,0 +1,639 @@
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/aws-sdk-go/aws"
+	"github.com/hashicorp/aws-sdk-go/gen/route53"
+	"github.com/hashicorp/terraform/helper/multierror"
+)
+
+func TestConfigResolveRegion(t *testing.T) {
+	defer resetEnv(append(regionEnvVars, ec2MetadataEndpointEnvVar))()
+	defer resetMetadataURL("")()
+	os.Setenv(ec2MetadataEndpointEnvVar, "")
+
+	cases := []struct {
+		Region string
+		Env    map[string]string
+		Result string
+	}{
+		{"us-west-2", map[string]string{"AWS_REGION": "eu-west-1"}, "us-west-2"},
+		{"", map[string]string{"AWS_REGION": "eu-west-1"}, "eu-west-1"},
+		{"", map[string]string{"AWS_DEFAULT_REGION": "ap-southeast-2"}, "ap-southeast-2"},
+		{
+			"",
+			map[string]string{
+				"AWS_REGION":         "eu-west-1",
+				"AWS_DEFAULT_REGION": "ap-southeast-2",
+			},
+			"eu-west-1",
+		},
+		{"", nil, ""},
+	}
+
+	for _, tc := range cases {
+		for _, k := range regionEnvVars {
+			os.Setenv(k, tc.Env[k])
+		}
+
+		c := &Config{Region: tc.Region}
+		c.resolveRegion()
+		if c.Region != tc.Result {
+			t.Fatalf("bad: %#v\n\n%s", tc, c.Region)
+		}
+	}
+}
+
+func TestConfigResolveRegion_metadata(t *testing.T) {
+	defer resetEnv(append(regionEnvVars, ec2MetadataEndpointEnvVar))()
+	os.Setenv(ec2MetadataEndpointEnvVar, "")
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/latest/meta-data/placement/availability-zone" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "us-west-2b")
+	}))
+	defer ts.Close()
+	defer resetMetadataURL(ts.URL + "/latest/meta-data/")()
+
+	for _, k := range regionEnvVars {
+		os.Setenv(k, "")
+	}
+
+	for i := 0; i < 2; i++ {
+		c := &Config{}
+		c.resolveRegion()
+		if c.Region != "us-west-2" {
+			t.Fatalf("bad: %s", c.Region)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected the region to be cached, got %d requests", requests)
+	}
+
+	// The environment still takes precedence.
+	os.Setenv("AWS_REGION", "eu-west-1")
+	c := &Config{}
+	c.resolveRegion()
+	if c.Region != "eu-west-1" {
+		t.Fatalf("bad: %s", c.Region)
+	}
+}
+
+func TestConfigResolveRegion_metadataEndpoint(t *testing.T) {
+	defer resetEnv(append(regionEnvVars, ec2MetadataEndpointEnvVar))()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latest/meta-data/placement/availability-zone" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "eu-central-1a")
+	}))
+	defer ts.Close()
+
+	for _, k := range regionEnvVars {
+		os.Setenv(k, "")
+	}
+
+	// From the config
+	c := &Config{EC2MetadataEndpoint: ts.URL}
+	c.resolveRegion()
+	if c.Region != "eu-central-1" {
+		t.Fatalf("bad: %s", c.Region)
+	}
+
+	// From the environment
+	os.Setenv(ec2MetadataEndpointEnvVar, ts.URL+"/")
+	c = &Config{}
+	if v := c.metadataURL(); v != ts.URL+"/latest/meta-data/" {
+		t.Fatalf("bad: %s", v)
+	}
+	c.resolveRegion()
+	if c.Region != "eu-central-1" {
+		t.Fatalf("bad: %s", c.Region)
+	}
+}
+
+func TestConfigValidateCredentials(t *testing.T) {
+	cases := []struct {
+		Code   string
+		Config Config
+		Err    bool
+	}{
+		{"", Config{}, false},
+		{"detect", Config{AccessKey: "a", SecretKey: "s"}, false},
+		{"", Config{CredentialsFileProfile: "p"}, false},
+		{"", Config{AccessKey: "a", SecretKey: "s", CredentialsFileProfile: "p"}, true},
+		{"static", Config{AccessKey: "a", SecretKey: "s", Token: "t"}, false},
+		{"static", Config{AccessKey: "a"}, true},
+		{"static", Config{AccessKey: "a", SecretKey: "s", CredentialsFilePath: "f"}, true},
+		{"iam", Config{}, false},
+		{"iam", Config{AccessKey: "a", SecretKey: "s"}, true},
+		{"env", Config{Token: "t"}, true},
+		{"env", Config{CredentialsFileProfile: "p"}, true},
+		{"file", Config{CredentialsFilePath: "f", CredentialsFileProfile: "p"}, false},
+		{"file", Config{AccessKey: "a", SecretKey: "s"}, true},
+		{"profile", Config{}, true},
+	}
+
+	for _, tc := range cases {
+		err := tc.Config.ValidateCredentials(tc.Code)
+		if (err != nil) != tc.Err {
+			t.Fatalf("bad: %q %#v\n\n%s", tc.Code, tc.Config, err)
+		}
+	}
+}
+
+func TestConfigCredsProviderCode(t *testing.T) {
+	cases := []struct {
+		Code   string
+		Config Config
+		Result string
+	}{
+		{"", Config{}, ""},
+		{"detect", Config{}, "detect"},
+		{"", Config{CredentialsFilePath: "f"}, "file"},
+		{"detect", Config{CredentialsFileProfile: "p"}, "file"},
+		{"static", Config{AccessKey: "a", SecretKey: "s"}, "static"},
+	}
+
+	for _, tc := range cases {
+		if v := tc.Config.credsProviderCode(tc.Code); v != tc.Result {
+			t.Fatalf("bad: %q %#v\n\n%s", tc.Code, tc.Config, v)
+		}
+	}
+}
+
+func TestConfigValidateRegion_empty(t *testing.T) {
+	c := &Config{}
+	if err := c.ValidateRegion(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestConfigValidateRegion_skip(t *testing.T) {
+	c := &Config{Region: "xx-newregion-1"}
+	if err := c.ValidateRegion(); err == nil {
+		t.Fatal("should error")
+	}
+
+	c.SkipRegionValidation = true
+	if err := c.ValidateRegion(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// A region is still required.
+	c.Region = ""
+	if err := c.ValidateRegion(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestConfigClient_errors(t *testing.T) {
+	c := &Config{
+		Region:          "not-a-region",
+		Route53Endpoint: "route53.example.com",
+		RequestTimeout:  -1 * time.Second,
+	}
+
+	_, err := c.Client()
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	merr, ok := err.(*multierror.Error)
+	if !ok {
+		t.Fatalf("bad: %#v", err)
+	}
+	// region, credentials provider, request timeout and Route53 endpoint
+	if len(merr.Errors) != 4 {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestConfigUnavailableServices(t *testing.T) {
+	c := &Config{Region: "us-east-1"}
+	if errs := c.unavailableServices(); len(errs) > 0 {
+		t.Fatalf("bad: %s", errs)
+	}
+
+	c.Region = "cn-north-1"
+	errs := c.unavailableServices()
+	if len(errs) != 1 {
+		t.Fatalf("bad: %s", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "Route53") {
+		t.Fatalf("bad: %s", errs[0])
+	}
+}
+
+func TestConfigClient_strictServiceRegions(t *testing.T) {
+	c := &Config{
+		Region:   "cn-north-1",
+		Provider: aws.Creds("foo", "bar", ""),
+	}
+	if _, err := c.Client(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	c.StrictServiceRegions = true
+	if _, err := c.Client(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestConfigClient_noRequests(t *testing.T) {
+	// Service connections are only structs until they are used: building
+	// the client must not talk to AWS.
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	c := &Config{
+		Region:          "us-east-1",
+		Route53Endpoint: ts.URL,
+		Provider:        aws.Creds("foo", "bar", ""),
+	}
+	if _, err := c.Client(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if requests != 0 {
+		t.Fatalf("bad: %d requests", requests)
+	}
+}
+
+func TestConfigGlobalRegion(t *testing.T) {
+	cases := []struct {
+		Region        string
+		SigningRegion string
+		Result        string
+	}{
+		{"us-east-1", "", "us-east-1"},
+		{"eu-west-1", "", "us-east-1"},
+		{"cn-north-1", "", "us-east-1"},
+		{"us-gov-west-1", "", "us-east-1"},
+		{"eu-west-1", "eu-west-1", "eu-west-1"},
+		{"cn-north-1", "us-east-1", "us-east-1"},
+	}
+
+	for _, tc := range cases {
+		c := &Config{Region: tc.Region, SigningRegion: tc.SigningRegion}
+		if actual := c.globalRegion(); actual != tc.Result {
+			t.Fatalf("bad: %s\n\n%s", tc.Region, actual)
+		}
+	}
+}
+
+func TestConfigClient_route53Endpoint(t *testing.T) {
+	c := &Config{
+		Region:          "us-east-1",
+		Route53Endpoint: "not a url",
+		Provider:        aws.Creds("foo", "bar", ""),
+	}
+	if _, err := c.Client(); err == nil {
+		t.Fatal("should error")
+	}
+
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<GetHostedZoneResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+  <HostedZone>
+    <Id>/hostedzone/Z123</Id>
+    <Name>example.com.</Name>
+  </HostedZone>
+</GetHostedZoneResponse>`)
+	}))
+	defer ts.Close()
+
+	c.Route53Endpoint = ts.URL
+	raw, err := c.Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	r53 := raw.(*AWSClient).r53conn
+	_, err = r53.GetHostedZone(&route53.GetHostedZoneRequest{ID: aws.String("Z123")})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(paths) != 1 || paths[0] != "/2013-04-01/hostedzone/Z123" {
+		t.Fatalf("request should go to the endpoint, got: %#v", paths)
+	}
+}
+
+func TestAWSClientRegions(t *testing.T) {
+	cases := []struct {
+		Region        string
+		Route53Region string
+		IAMRegion     string
+	}{
+		{"us-east-1", "us-east-1", "us-east-1"},
+		{"eu-west-1", "us-east-1", "us-east-1"},
+		{"cn-north-1", "us-east-1", "cn-north-1"},
+		{"us-gov-west-1", "us-east-1", "us-gov-west-1"},
+	}
+
+	for _, tc := range cases {
+		c := &Config{
+			Region:   tc.Region,
+			Provider: aws.Creds("foo", "bar", ""),
+		}
+		raw, err := c.Client()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		client := raw.(*AWSClient)
+
+		regional := map[string]string{
+			"EC2":         client.EC2Region(),
+			"ELB":         client.ELBRegion(),
+			"AutoScaling": client.AutoScalingRegion(),
+			"S3":          client.S3Region(),
+			"RDS":         client.RDSRegion(),
+		}
+		for name, actual := range regional {
+			if actual != tc.Region {
+				t.Fatalf("bad %s region for %s: %s", name, tc.Region, actual)
+			}
+		}
+
+		if actual := client.Route53Region(); actual != tc.Route53Region {
+			t.Fatalf("bad Route53 region for %s: %s", tc.Region, actual)
+		}
+		if actual := client.IAMRegion(); actual != tc.IAMRegion {
+			t.Fatalf("bad IAM region for %s: %s", tc.Region, actual)
+		}
+	}
+}
+
+func TestConfigClient_signingRegion(t *testing.T) {
+	c := &Config{
+		Region:        "eu-west-1",
+		SigningRegion: "eu-west-1",
+		Provider:      aws.Creds("foo", "bar", ""),
+	}
+	raw, err := c.Client()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	client := raw.(*AWSClient)
+
+	if v := client.Route53Region(); v != "eu-west-1" {
+		t.Fatalf("bad: %s", v)
+	}
+
+	// The SDK always signs IAM for the partition's region
+	if v := client.IAMRegion(); v != "us-east-1" {
+		t.Fatalf("bad: %s", v)
+	}
+}
+
+func TestAWSClientAccountID(t *testing.T) {
+	calls := 0
+	c := &AWSClient{
+		callerIdentityFunc: func() (string, string, error) {
+			calls++
+			return "123456789012", "AIDAEXAMPLE", nil
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		accountID, err := c.AccountID()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if accountID != "123456789012" {
+			t.Fatalf("bad: %s", accountID)
+		}
+	}
+
+	userID, err := c.UserID()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if userID != "AIDAEXAMPLE" {
+		t.Fatalf("bad: %s", userID)
+	}
+
+	if calls != 1 {
+		t.Fatalf("should look up the caller once, did %d times", calls)
+	}
+}
+
+func TestAWSClientAccountID_retry(t *testing.T) {
+	calls := 0
+	c := &AWSClient{
+		callerIdentityFunc: func() (string, string, error) {
+			calls++
+			if calls == 1 {
+				return "", "", fmt.Errorf("Throttling: Rate exceeded")
+			}
+			return "123456789012", "AIDAEXAMPLE", nil
+		},
+	}
+
+	if _, err := c.AccountID(); err == nil {
+		t.Fatal("should error")
+	}
+
+	// The failure isn't cached
+	accountID, err := c.AccountID()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if accountID != "123456789012" {
+		t.Fatalf("bad: %s", accountID)
+	}
+	if calls != 2 {
+		t.Fatalf("bad: %d", calls)
+	}
+}
+
+func TestAWSClientAccountID_credentialsChanged(t *testing.T) {
+	calls := 0
+	c := &AWSClient{
+		credsProvider: aws.Creds("AKIDONE", "secret", ""),
+		callerIdentityFunc: func() (string, string, error) {
+			calls++
+			return fmt.Sprintf("%012d", calls), "AIDAEXAMPLE", nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, _ := c.AccountID(); v != "000000000001" {
+			t.Fatalf("bad: %s", v)
+		}
+	}
+
+	// Credentials refreshed with a different key are looked up again
+	c.credsProvider = aws.Creds("AKIDTWO", "secret", "")
+	if v, _ := c.AccountID(); v != "000000000002" {
+		t.Fatalf("bad: %s", v)
+	}
+	if calls != 2 {
+		t.Fatalf("bad: %d", calls)
+	}
+}
+
+func TestAccountIDFromARN(t *testing.T) {
+	cases := []struct {
+		ARN       string
+		AccountID string
+		Err       bool
+	}{
+		{"arn:aws:iam::123456789012:user/foo", "123456789012", false},
+		{"arn:aws-cn:iam::123456789012:user/path/to/foo", "123456789012", false},
+		{"arn:aws:iam::123456789012:root", "123456789012", false},
+		{"arn:aws:s3:::bucket", "", true},
+		{"not an arn", "", true},
+	}
+
+	for _, tc := range cases {
+		actual, err := accountIDFromARN(tc.ARN)
+		if (err != nil) != tc.Err {
+			t.Fatalf("bad: %s\n\n%s", tc.ARN, err)
+		}
+		if actual != tc.AccountID {
+			t.Fatalf("bad: %s\n\n%s", tc.ARN, actual)
+		}
+	}
+}
+
+func TestConfigHTTPClient(t *testing.T) {
+	c := &Config{}
+	if client := c.httpClient(); client != nil {
+		t.Fatalf("bad: %#v", client)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	c = &Config{
+		RequestTimeout: 50 * time.Millisecond,
+		DialTimeout:    50 * time.Millisecond,
+	}
+	client := c.httpClient()
+	if client == nil {
+		t.Fatal("should have a client")
+	}
+
+	start := time.Now()
+	if _, err := client.Get(ts.URL); err == nil {
+		t.Fatal("should time out")
+	}
+	if d := time.Since(start); d >= 500*time.Millisecond {
+		t.Fatalf("took too long: %s", d)
+	}
+}
+
+func TestEndpointClient(t *testing.T) {
+	var host, path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		path = r.URL.Path
+	}))
+	defer ts.Close()
+
+	client, err := endpointClient(ts.URL, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	resp, err := client.Get("https://route53.amazonaws.com/2013-04-01/hostedzone")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+
+	if path != "/2013-04-01/hostedzone" {
+		t.Fatalf("bad path: %s", path)
+	}
+	if host != "route53.amazonaws.com" {
+		t.Fatalf("bad host: %s", host)
+	}
+}
+
+func TestEndpointClient_base(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	c := &Config{RequestTimeout: 50 * time.Millisecond}
+	client, err := endpointClient(ts.URL, c.httpClient())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := client.Get("https://route53.amazonaws.com/"); err == nil {
+		t.Fatal("should time out")
+	}
+}
+
+func TestEndpointClient_empty(t *testing.T) {
+	client, err := endpointClient("", nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if client != nil {
+		t.Fatalf("bad: %#v", client)
+	}
+}
+
+func TestEndpointClient_invalid(t *testing.T) {
+	cases := []string{
+		"localhost:4580",
+		"route53.example.com",
+		"://",
+	}
+
+	for _, tc := range cases {
+		if _, err := endpointClient(tc, nil); err == nil {
+			t.Fatalf("should error: %s", tc)
+		}
+	}
+}
+
+// resetEnv saves the given environment variables and returns a func that
+// restores them, for use with defer.
+func resetEnv(keys []string) func() {
+	saved := make(map[string]string)
+	for _, k := range keys {
+		saved[k] = os.Getenv(k)
+	}
+
+	return func() {
+		for k, v := range saved {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+// resetMetadataURL points the EC2 metadata lookup at url and returns a
+// function that restores it.
+func resetMetadataURL(url string) func() {
+	old := ec2MetadataURL
+	ec2MetadataURL = url
+	return func() {
+		ec2MetadataURL = old
+	}
+}
@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/aws-sdk-go/aws"
+)
+
+// metadataCredsRefresh is how long before they expire that instance profile
+// credentials are fetched again.
+const metadataCredsRefresh = 5 * time.Minute
+
+// metadataCreds is an aws.CredentialsProvider for the credentials of the
+// instance profile, read from the instance metadata service at the given
+// base URL. aws.IAMCreds always uses the default address of the service,
+// which doesn't work with metadata proxies such as kube2iam.
+type metadataCreds struct {
+	url    string
+	client *http.Client
+
+	l       sync.Mutex
+	creds   *aws.Credentials
+	expires time.Time
+}
+
+func newMetadataCreds(url string) *metadataCreds {
+	return &metadataCreds{
+		url:    strings.TrimSuffix(url, "/") + "/iam/security-credentials/",
+		client: &http.Client{Timeout: ec2MetadataTimeout},
+	}
+}
+
+// Credentials returns the instance profile credentials, fetching them again
+// shortly before they expire.
+func (p *metadataCreds) Credentials() (*aws.Credentials, error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if p.creds != nil && time.Now().Before(p.expires.Add(-metadataCredsRefresh)) {
+		return p.creds, nil
+	}
+
+	roles, err := p.get(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing instance profile roles: %s", err)
+	}
+	defer roles.Close()
+
+	s := bufio.NewScanner(roles)
+	if !s.Scan() {
+		return nil, fmt.Errorf("No instance profile role found at %s", p.url)
+	}
+	role := strings.TrimSpace(s.Text())
+
+	body, err := p.get(p.url + role)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting credentials for role %s: %s", role, err)
+	}
+	defer body.Close()
+
+	var resp struct {
+		Code            string
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("Error decoding credentials for role %s: %s", role, err)
+	}
+	if resp.Code != "Success" {
+		return nil, fmt.Errorf("Error getting credentials for role %s: %s", role, resp.Code)
+	}
+
+	p.creds = &aws.Credentials{
+		AccessKeyID:     resp.AccessKeyID,
+		SecretAccessKey: resp.SecretAccessKey,
+		SecurityToken:   resp.Token,
+	}
+	p.expires = resp.Expiration
+
+	return p.creds, nil
+}
+
+func (p *metadataCreds) get(url string) (io.ReadCloser, error) {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,169 @@
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/aws-sdk-go/aws"
+)
+
+// testMetadataServer returns a mock instance metadata service with the
+// credentials of the role "web", and a pointer to the number of credential
+// requests it served.
+func testMetadataServer(expires time.Time) (*httptest.Server, *int) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprintln(w, "web")
+		case "/latest/meta-data/iam/security-credentials/web":
+			requests++
+			fmt.Fprintf(w, `{
+  "Code" : "Success",
+  "Type" : "AWS-HMAC",
+  "AccessKeyId" : "AKIDEXAMPLE%d",
+  "SecretAccessKey" : "secret",
+  "Token" : "token",
+  "Expiration" : "%s"
+}`, requests, expires.UTC().Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return ts, &requests
+}
+
+func TestMetadataCreds(t *testing.T) {
+	ts, requests := testMetadataServer(time.Now().Add(time.Hour))
+	defer ts.Close()
+
+	p := newMetadataCreds(ts.URL + "/latest/meta-data/")
+	for i := 0; i < 2; i++ {
+		creds, err := p.Credentials()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if creds.AccessKeyID != "AKIDEXAMPLE1" ||
+			creds.SecretAccessKey != "secret" ||
+			creds.SecurityToken != "token" {
+			t.Fatalf("bad: %#v", creds)
+		}
+	}
+
+	if *requests != 1 {
+		t.Fatalf("should cache the credentials, got %d requests", *requests)
+	}
+}
+
+func TestMetadataCreds_expiring(t *testing.T) {
+	ts, requests := testMetadataServer(time.Now().Add(time.Minute))
+	defer ts.Close()
+
+	p := newMetadataCreds(ts.URL + "/latest/meta-data/")
+	for i := 0; i < 2; i++ {
+		if _, err := p.Credentials(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	if *requests != 2 {
+		t.Fatalf("should refresh expiring credentials, got %d requests", *requests)
+	}
+}
+
+func TestMetadataCreds_noRole(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	p := newMetadataCreds(ts.URL + "/latest/meta-data/")
+	if _, err := p.Credentials(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestConfigGetCredsProvider_metadataEndpoint(t *testing.T) {
+	defer resetEnv([]string{ec2MetadataEndpointEnvVar})()
+	os.Setenv(ec2MetadataEndpointEnvVar, "")
+
+	ts, _ := testMetadataServer(time.Now().Add(time.Hour))
+	defer ts.Close()
+
+	c := &Config{EC2MetadataEndpoint: ts.URL}
+	p, err := c.getCredsProvider("iam")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	creds, err := p.Credentials()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if creds.AccessKeyID != "AKIDEXAMPLE1" {
+		t.Fatalf("bad: %#v", creds)
+	}
+
+	// Without an endpoint the SDK's own provider is used
+	c.EC2MetadataEndpoint = ""
+	p, err = c.getCredsProvider("iam")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := p.(*metadataCreds); ok {
+		t.Fatalf("bad: %#v", p)
+	}
+}
+
+func TestConfigGetCredsProvider_metadataEndpointDetect(t *testing.T) {
+	envCreds := []string{
+		"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY",
+		"AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY",
+		"AWS_SESSION_TOKEN", ec2MetadataEndpointEnvVar,
+	}
+	defer resetEnv(envCreds)()
+	for _, k := range envCreds {
+		os.Setenv(k, "")
+	}
+
+	// The default credentials file takes precedence over the instance
+	// profile, so this can only be tested without one.
+	if p, err := aws.ProfileCreds("", "", 0); err == nil {
+		if _, err := p.Credentials(); err == nil {
+			t.Skip("a default credentials file exists")
+		}
+	}
+
+	ts, _ := testMetadataServer(time.Now().Add(time.Hour))
+	defer ts.Close()
+
+	c := &Config{EC2MetadataEndpoint: ts.URL}
+	p, err := c.getCredsProvider("detect")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	creds, err := p.Credentials()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if creds.AccessKeyID != "AKIDEXAMPLE1" {
+		t.Fatalf("bad: %#v", creds)
+	}
+
+	// Credentials in the environment take precedence.
+	os.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	p, err = c.getCredsProvider("detect")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	creds, err = p.Credentials()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if creds.AccessKeyID != "AKIDENV" {
+		t.Fatalf("bad: %#v", creds)
+	}
+}
@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
@@ -57,6 +59,55 @@ func Provider() terraform.ResourceProvider {
 				Description:  descriptions["region"],
 				InputDefault: "us-east-1",
 			},
+
+			"route53_endpoint": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: descriptions["route53_endpoint"],
+			},
+
+			"request_timeout": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: descriptions["request_timeout"],
+			},
+
+			"dial_timeout": &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: descriptions["dial_timeout"],
+			},
+
+			"signing_region": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: descriptions["signing_region"],
+			},
+
+			"strict_service_regions": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["strict_service_regions"],
+			},
+
+			"skip_region_validation": &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["skip_region_validation"],
+			},
+
+			"ec2_metadata_endpoint": &schema.Schema{
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: descriptions["ec2_metadata_endpoint"],
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
@@ -113,6 +164,27 @@ func init() {
 
 		"credentials_file_profile": "Profile name in a credentials file." +
 			"Default is 'default'. Implies credentials_provider=file",
+
+		"route53_endpoint": "Use this to override the default Route53 endpoint URL,\n" +
+			"e.g. to use a mock.",
+
+		"request_timeout": "The maximum time in seconds that a single request\n" +
+			"to AWS may take. Defaults to no limit.",
+
+		"dial_timeout": "The maximum time in seconds to wait for a connection\n" +
+			"to AWS. Defaults to the operating system's limit.",
+
+		"signing_region": "The region that Route53 requests are signed for.\n" +
+			"Defaults to us-east-1.",
+
+		"strict_service_regions": "Fail, rather than warn, if a service that\n" +
+			"Terraform uses isn't offered in the region.",
+
+		"skip_region_validation": "Skip checking the region against the list of\n" +
+			"known AWS regions, e.g. for new regions or mocks.",
+
+		"ec2_metadata_endpoint": "The address of the EC2 instance metadata service\n" +
+			"used for instance profile credentials and the region, e.g. a metadata proxy.",
 	}
 }
 
@@ -124,6 +196,13 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		CredentialsFilePath:    d.Get("credentials_file_path").(string),
 		CredentialsFileProfile: d.Get("credentials_file_profile").(string),
 		Region:                 d.Get("region").(string),
+		Route53Endpoint:        d.Get("route53_endpoint").(string),
+		RequestTimeout:         time.Duration(d.Get("request_timeout").(int)) * time.Second,
+		DialTimeout:            time.Duration(d.Get("dial_timeout").(int)) * time.Second,
+		SigningRegion:          d.Get("signing_region").(string),
+		StrictServiceRegions:   d.Get("strict_service_regions").(bool),
+		SkipRegionValidation:   d.Get("skip_region_validation").(bool),
+		EC2MetadataEndpoint:    d.Get("ec2_metadata_endpoint").(string),
 	}
 
 	return config.loadAndValidate(d.Get("credentials_provider").(string))
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/hashicorp/aws-sdk-go/aws"
-	"github.com/hashicorp/aws-sdk-go/gen/iam"
 	"github.com/hashicorp/aws-sdk-go/gen/rds"
 
 	"github.com/hashicorp/terraform/helper/hashcode"
@@ -536,14 +535,11 @@ func resourceAwsDbInstanceStateRefreshFunc(
 }
 
 func buildRDSARN(d *schema.ResourceData, meta interface{}) (string, error) {
-	iamconn := meta.(*AWSClient).iamconn
 	region := meta.(*AWSClient).region
-	// An zero value GetUserRequest{} defers to the currently logged in user
-	resp, err := iamconn.GetUser(&iam.GetUserRequest{})
+	accountID, err := meta.(*AWSClient).AccountID()
 	if err != nil {
 		return "", err
 	}
-	user := resp.User
-	arn := fmt.Sprintf("arn:aws:rds:%s:%s:db:%s", region, *user.UserID, d.Id())
+	arn := fmt.Sprintf("arn:aws:rds:%s:%s:db:%s", region, accountID, d.Id())
 	return arn, nil
 }
@@ -86,8 +86,21 @@ func resourceAwsInstance() *schema.Resource {
 				StateFunc: func(v interface{}) string {
 					switch v.(type) {
 					case string:
-						hash := sha1.Sum([]byte(v.(string)))
-						return hex.EncodeToString(hash[:])
+						return userDataHashSum(v.(string))
+					default:
+						return ""
+					}
+				},
+			},
+
+			"user_data_base64": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				StateFunc: func(v interface{}) string {
+					switch v.(type) {
+					case string:
+						return userDataBase64HashSum(v.(string))
 					default:
 						return ""
 					}
@@ -265,6 +278,13 @@ func resourceAwsInstance() *schema.Resource {
 							ForceNew: true,
 						},
 
+						// The root volume is only encrypted if the AMI's root
+						// snapshot is, so this can't be set, only read.
+						"encrypted": &schema.Schema{
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
 						"iops": &schema.Schema{
 							Type:     schema.TypeInt,
 							Optional: true,
@@ -306,9 +326,10 @@ func resourceAwsInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 	ec2conn := meta.(*AWSClient).ec2conn
 
 	// Figure out user data
-	userData := ""
-	if v := d.Get("user_data"); v != nil {
-		userData = base64.StdEncoding.EncodeToString([]byte(v.(string)))
+	userData, err := userDataBase64(
+		d.Get("user_data").(string), d.Get("user_data_base64").(string))
+	if err != nil {
+		return err
 	}
 
 	// check for non-default Subnet, and cast it to a String
@@ -413,6 +434,12 @@ func resourceAwsInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 				DeleteOnTermination: aws.Boolean(bd["delete_on_termination"].(bool)),
 			}
 
+			if v, ok := bd["encrypted"].(bool); ok && v {
+				// Without a KMS key this uses the account's default
+				// EBS encryption key.
+				ebs.Encrypted = aws.Boolean(v)
+			}
+
 			if v, ok := bd["snapshot_id"].(string); ok && v != "" {
 				ebs.SnapshotID = aws.String(v)
 			}
@@ -429,6 +456,11 @@ func resourceAwsInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 				ebs.IOPS = aws.Integer(v)
 			}
 
+			if err := validateBlockDeviceIops(bd); err != nil {
+				return fmt.Errorf(
+					"Error in ebs_block_device %q: %s", bd["device_name"].(string), err)
+			}
+
 			blockDevices = append(blockDevices, ec2.BlockDeviceMapping{
 				DeviceName: aws.String(bd["device_name"].(string)),
 				EBS:        ebs,
@@ -470,6 +502,10 @@ func resourceAwsInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 				ebs.IOPS = aws.Integer(v)
 			}
 
+			if err := validateBlockDeviceIops(bd); err != nil {
+				return fmt.Errorf("Error in root_block_device: %s", err)
+			}
+
 			if dn, err := fetchRootDeviceName(d.Get("ami").(string), ec2conn); err == nil {
 				blockDevices = append(blockDevices, ec2.BlockDeviceMapping{
 					DeviceName: dn,
@@ -783,6 +819,9 @@ func readBlockDevicesFromInstance(instance *ec2.Instance, ec2conn *ec2.EC2) (map
 		if vol.IOPS != nil {
 			bd["iops"] = *vol.IOPS
 		}
+		if vol.Encrypted != nil {
+			bd["encrypted"] = *vol.Encrypted
+		}
 
 		if blockDeviceIsRoot(instanceBd, instance) {
 			blockDevices["root"] = bd
@@ -790,9 +829,6 @@ func readBlockDevicesFromInstance(instance *ec2.Instance, ec2conn *ec2.EC2) (map
 			if instanceBd.DeviceName != nil {
 				bd["device_name"] = *instanceBd.DeviceName
 			}
-			if vol.Encrypted != nil {
-				bd["encrypted"] = *vol.Encrypted
-			}
 			if vol.SnapshotID != nil {
 				bd["snapshot_id"] = *vol.SnapshotID
 			}
@@ -810,6 +846,89 @@ func blockDeviceIsRoot(bd ec2.InstanceBlockDeviceMapping, instance *ec2.Instance
 		*bd.DeviceName == *instance.RootDeviceName)
 }
 
+// blockDeviceIopsRanges are the minimum and maximum iops allowed for each
+// volume_type that takes iops. io2 allows up to 256000 on Block Express.
+var blockDeviceIopsRanges = map[string][2]int{
+	"io1": {100, 64000},
+	"io2": {100, 256000},
+	"gp3": {3000, 16000},
+}
+
+// validateBlockDeviceIops checks that the iops of a block device agree with
+// its volume_type: iops may only be set for provisioned IOPS (io1, io2) and
+// gp3 volumes, must be set for provisioned IOPS volumes, and must be within
+// the range for the type. AWS rejects the launch otherwise, but with a far
+// less helpful message.
+func validateBlockDeviceIops(bd map[string]interface{}) error {
+	volumeType, _ := bd["volume_type"].(string)
+	iops, _ := bd["iops"].(int)
+
+	switch volumeType {
+	case "io1", "io2":
+		if iops <= 0 {
+			return fmt.Errorf("iops must be set for volume_type %q", volumeType)
+		}
+	case "gp3":
+		if iops <= 0 {
+			return nil
+		}
+	default:
+		if iops > 0 {
+			return fmt.Errorf(
+				"iops can only be set for volume_type io1, io2 or gp3, not %q",
+				volumeType)
+		}
+		return nil
+	}
+
+	r := blockDeviceIopsRanges[volumeType]
+	if iops < r[0] || iops > r[1] {
+		return fmt.Errorf(
+			"iops for volume_type %q must be between %d and %d, got %d",
+			volumeType, r[0], r[1], iops)
+	}
+
+	return nil
+}
+
+// userDataBase64 returns the user data for the EC2 API, which expects it
+// base64 encoded, from the user_data and user_data_base64 attributes.
+// user_data is always encoded, while user_data_base64 was encoded by the
+// user and is sent as is.
+func userDataBase64(userData, encoded string) (string, error) {
+	switch {
+	case userData != "" && encoded != "":
+		return "", fmt.Errorf("Only one of user_data and user_data_base64 can be set")
+	case encoded != "":
+		if _, err := base64.StdEncoding.DecodeString(encoded); err != nil {
+			return "", fmt.Errorf("user_data_base64 is not valid base64: %s", err)
+		}
+		return encoded, nil
+	default:
+		return base64.StdEncoding.EncodeToString([]byte(userData)), nil
+	}
+}
+
+// userDataHashSum returns the hash of user_data that is stored in the
+// state.
+func userDataHashSum(userData string) string {
+	hash := sha1.Sum([]byte(userData))
+	return hex.EncodeToString(hash[:])
+}
+
+// userDataBase64HashSum returns the hash of user_data_base64 that is stored
+// in the state. Like user_data's, the hash is of the content rather than
+// its encoding. The two are separate ForceNew attributes though, so moving
+// the same content from one to the other still forces a new resource.
+func userDataBase64HashSum(encoded string) string {
+	v, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return userDataHashSum(encoded)
+	}
+
+	return userDataHashSum(string(v))
+}
+
 func fetchRootDeviceName(ami string, conn *ec2.EC2) (aws.StringValue, error) {
 	if ami == "" {
 		return nil, fmt.Errorf("Cannot fetch root device name for blank AMI ID.")
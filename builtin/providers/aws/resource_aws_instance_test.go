@@ -172,6 +172,36 @@ func TestAccAWSInstance_blockDevices(t *testing.T) {
 	})
 }
 
+func TestAccAWSInstance_ebsEncrypted(t *testing.T) {
+	var v ec2.Instance
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			resource.TestStep{
+				Config: testAccInstanceConfigEbsEncrypted,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckInstanceExists(
+						"aws_instance.foo", &v),
+					resource.TestCheckResourceAttr(
+						"aws_instance.foo", "root_block_device.#", "1"),
+					// The AMI's root snapshot isn't encrypted
+					resource.TestCheckResourceAttr(
+						"aws_instance.foo", "root_block_device.1023169747.encrypted", "false"),
+					resource.TestCheckResourceAttr(
+						"aws_instance.foo", "ebs_block_device.#", "1"),
+					resource.TestCheckResourceAttr(
+						"aws_instance.foo", "ebs_block_device.1877380657.device_name", "/dev/sdb"),
+					resource.TestCheckResourceAttr(
+						"aws_instance.foo", "ebs_block_device.1877380657.encrypted", "true"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccAWSInstance_sourceDestCheck(t *testing.T) {
 	var v ec2.Instance
 
@@ -420,6 +450,99 @@ func TestInstanceTenancySchema(t *testing.T) {
 	}
 }
 
+func TestValidateBlockDeviceIops(t *testing.T) {
+	cases := []struct {
+		VolumeType string
+		Iops       int
+		Err        bool
+	}{
+		{"", 0, false},
+		{"standard", 0, false},
+		{"gp2", 0, false},
+		{"gp3", 0, false},
+		{"gp3", 3000, false},
+		{"io1", 1000, false},
+		{"io2", 1000, false},
+		{"gp3", 16000, false},
+		{"io1", 64000, false},
+		{"io2", 100, false},
+		{"io2", 256000, false},
+		{"", 1000, true},
+		{"standard", 1000, true},
+		{"gp2", 1000, true},
+		{"io1", 0, true},
+		{"io2", 0, true},
+		{"gp3", 2999, true},
+		{"gp3", 16001, true},
+		{"io1", 99, true},
+		{"io1", 64001, true},
+		{"io2", 256001, true},
+	}
+
+	for _, tc := range cases {
+		err := validateBlockDeviceIops(map[string]interface{}{
+			"volume_type": tc.VolumeType,
+			"iops":        tc.Iops,
+		})
+		if (err != nil) != tc.Err {
+			t.Fatalf("bad: %s/%d: %s", tc.VolumeType, tc.Iops, err)
+		}
+	}
+}
+
+func TestUserDataHashSum(t *testing.T) {
+	// Hashes of user_data must not change, or existing state would show a
+	// diff. Plain text that happens to be valid base64 is hashed as is.
+	cases := map[string]string{
+		"foo":      "0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33",
+		"instance": "c3bec6bcbc9b9f04e60fcb1d9c9c1a37f3e12e93",
+	}
+	for input, hash := range cases {
+		if v := userDataHashSum(input); v != hash {
+			t.Fatalf("bad: %q\n\n%s", input, v)
+		}
+	}
+}
+
+func TestUserDataBase64HashSum(t *testing.T) {
+	plain := "#!/bin/bash\necho hello\n"
+	encoded := "IyEvYmluL2Jhc2gKZWNobyBoZWxsbwo="
+
+	if userDataHashSum(plain) != userDataBase64HashSum(encoded) {
+		t.Fatalf("plain and base64 user data should hash the same")
+	}
+	if userDataBase64HashSum(encoded) == userDataHashSum("#!/bin/bash\necho bye\n") {
+		t.Fatalf("different user data should hash differently")
+	}
+}
+
+func TestUserDataBase64(t *testing.T) {
+	cases := []struct {
+		UserData string
+		Encoded  string
+		Output   string
+		Err      bool
+	}{
+		{"#!/bin/bash\necho hello\n", "", "IyEvYmluL2Jhc2gKZWNobyBoZWxsbwo=", false},
+		// Plain text that is also valid base64 is still encoded
+		{"instance", "", "aW5zdGFuY2U=", false},
+		{"", "IyEvYmluL2Jhc2gKZWNobyBoZWxsbwo=", "IyEvYmluL2Jhc2gKZWNobyBoZWxsbwo=", false},
+		{"", "", "", false},
+		{"", "not base64!", "", true},
+		{"foo", "Zm9v", "", true},
+	}
+
+	for _, tc := range cases {
+		actual, err := userDataBase64(tc.UserData, tc.Encoded)
+		if (err != nil) != tc.Err {
+			t.Fatalf("bad: %#v\n\n%s", tc, err)
+		}
+		if actual != tc.Output {
+			t.Fatalf("bad: %#v\n\n%s", tc, actual)
+		}
+	}
+}
+
 const testAccInstanceConfig_pre = `
 resource "aws_security_group" "tf_test_foo" {
 	name = "tf_test_foo"
@@ -485,6 +608,24 @@ resource "aws_instance" "foo" {
 }
 `
 
+const testAccInstanceConfigEbsEncrypted = `
+resource "aws_instance" "foo" {
+	# us-west-2
+	ami = "ami-55a7ea65"
+	instance_type = "m3.medium"
+
+	root_block_device {
+		volume_type = "gp2"
+		volume_size = 11
+	}
+	ebs_block_device {
+		device_name = "/dev/sdb"
+		volume_size = 9
+		encrypted = true
+	}
+}
+`
+
 const testAccInstanceConfigSourceDestEnable = `
 resource "aws_vpc" "foo" {
 	cidr_block = "10.1.0.0/16"
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"crypto/sha1"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"log"
 	"time"
@@ -63,8 +62,21 @@ func resourceAwsLaunchConfiguration() *schema.Resource {
 				StateFunc: func(v interface{}) string {
 					switch v.(type) {
 					case string:
-						hash := sha1.Sum([]byte(v.(string)))
-						return hex.EncodeToString(hash[:])
+						return userDataHashSum(v.(string))
+					default:
+						return ""
+					}
+				},
+			},
+
+			"user_data_base64": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				StateFunc: func(v interface{}) string {
+					switch v.(type) {
+					case string:
+						return userDataBase64HashSum(v.(string))
 					default:
 						return ""
 					}
@@ -264,8 +276,12 @@ func resourceAwsLaunchConfigurationCreate(d *schema.ResourceData, meta interface
 		EBSOptimized:            aws.Boolean(d.Get("ebs_optimized").(bool)),
 	}
 
-	if v, ok := d.GetOk("user_data"); ok {
-		userData := base64.StdEncoding.EncodeToString([]byte(v.(string)))
+	userData, err := userDataBase64(
+		d.Get("user_data").(string), d.Get("user_data_base64").(string))
+	if err != nil {
+		return err
+	}
+	if userData != "" {
 		createLaunchConfigurationOpts.UserData = aws.String(userData)
 	}
 
@@ -322,6 +338,11 @@ func resourceAwsLaunchConfigurationCreate(d *schema.ResourceData, meta interface
 				ebs.IOPS = aws.Integer(v)
 			}
 
+			if err := validateBlockDeviceIops(bd); err != nil {
+				return fmt.Errorf(
+					"Error in ebs_block_device %q: %s", bd["device_name"].(string), err)
+			}
+
 			blockDevices = append(blockDevices, autoscaling.BlockDeviceMapping{
 				DeviceName: aws.String(bd["device_name"].(string)),
 				EBS:        ebs,
@@ -363,6 +384,10 @@ func resourceAwsLaunchConfigurationCreate(d *schema.ResourceData, meta interface
 				ebs.IOPS = aws.Integer(v)
 			}
 
+			if err := validateBlockDeviceIops(bd); err != nil {
+				return fmt.Errorf("Error in root_block_device: %s", err)
+			}
+
 			if dn, err := fetchRootDeviceName(d.Get("image_id").(string), ec2conn); err == nil {
 				blockDevices = append(blockDevices, autoscaling.BlockDeviceMapping{
 					DeviceName: dn,
@@ -390,7 +415,7 @@ func resourceAwsLaunchConfigurationCreate(d *schema.ResourceData, meta interface
 	}
 
 	log.Printf("[DEBUG] autoscaling create launch configuration: %#v", createLaunchConfigurationOpts)
-	err := autoscalingconn.CreateLaunchConfiguration(&createLaunchConfigurationOpts)
+	err = autoscalingconn.CreateLaunchConfiguration(&createLaunchConfigurationOpts)
 	if err != nil {
 		return fmt.Errorf("Error creating launch configuration: %s", err)
 	}
@@ -67,11 +67,18 @@ func resourceAwsS3BucketCreate(d *schema.ResourceData, meta interface{}) error {
 	// Assign the bucket name as the resource ID
 	d.SetId(bucket)
 
+	// The bucket was just created in our region, which GetBucketLocation
+	// may not report yet.
+	meta.(*AWSClient).setS3BucketRegion(bucket, awsRegion)
+
 	return resourceAwsS3BucketUpdate(d, meta)
 }
 
 func resourceAwsS3BucketUpdate(d *schema.ResourceData, meta interface{}) error {
-	s3conn := meta.(*AWSClient).s3conn
+	s3conn, err := meta.(*AWSClient).S3ForBucket(d.Id())
+	if err != nil {
+		return err
+	}
 	if err := setTagsS3(s3conn, d); err != nil {
 		return err
 	}
@@ -79,9 +86,12 @@ func resourceAwsS3BucketUpdate(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAwsS3BucketRead(d *schema.ResourceData, meta interface{}) error {
-	s3conn := meta.(*AWSClient).s3conn
+	s3conn, err := meta.(*AWSClient).S3ForBucket(d.Id())
+	if err != nil {
+		return err
+	}
 
-	err := s3conn.HeadBucket(&s3.HeadBucketRequest{
+	err = s3conn.HeadBucket(&s3.HeadBucketRequest{
 		Bucket: aws.String(d.Id()),
 	})
 	if err != nil {
@@ -101,10 +111,13 @@ func resourceAwsS3BucketRead(d *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceAwsS3BucketDelete(d *schema.ResourceData, meta interface{}) error {
-	s3conn := meta.(*AWSClient).s3conn
+	s3conn, err := meta.(*AWSClient).S3ForBucket(d.Id())
+	if err != nil {
+		return err
+	}
 
 	log.Printf("[DEBUG] S3 Delete Bucket: %s", d.Id())
-	err := s3conn.DeleteBucket(&s3.DeleteBucketRequest{
+	err = s3conn.DeleteBucket(&s3.DeleteBucketRequest{
 		Bucket: aws.String(d.Id()),
 	})
 	if err != nil {
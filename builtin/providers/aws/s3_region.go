@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/aws-sdk-go/aws"
+	"github.com/hashicorp/aws-sdk-go/gen/s3"
+)
+
+// S3ForBucket returns an S3 connection for the region that the given
+// bucket lives in. Requests for a bucket sent to another region's endpoint
+// fail with a PermanentRedirect, so this must be used for any operation on
+// a bucket that may not be in the provider's region. Connections to other
+// regions are made once and shared by all buckets in that region.
+func (c *AWSClient) S3ForBucket(bucket string) (*s3.S3, error) {
+	region, err := c.s3BucketRegion(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if region == c.region {
+		return c.s3conn, nil
+	}
+
+	c.s3lock.Lock()
+	defer c.s3lock.Unlock()
+
+	if conn, ok := c.s3conns[region]; ok {
+		return conn, nil
+	}
+
+	log.Printf("[DEBUG] S3 bucket %s is in %s, connecting to that region", bucket, region)
+	conn := s3.New(c.credsProvider, region, c.httpClient)
+	if c.s3conns == nil {
+		c.s3conns = make(map[string]*s3.S3)
+	}
+	c.s3conns[region] = conn
+
+	return conn, nil
+}
+
+// s3BucketRegion returns the region the given bucket lives in. A bucket
+// can't move between regions, so the result is cached for the lifetime of
+// the client. The lock isn't held while the region is looked up, so that
+// operations on other buckets aren't held up by it. If the lookup is
+// denied, the bucket is assumed to be in the provider's region.
+func (c *AWSClient) s3BucketRegion(bucket string) (string, error) {
+	c.s3lock.Lock()
+	region, ok := c.s3BucketRegions[bucket]
+	c.s3lock.Unlock()
+	if ok {
+		return region, nil
+	}
+
+	resp, err := c.s3conn.GetBucketLocation(&s3.GetBucketLocationRequest{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		// Policies that predate locating buckets may not allow it. Such
+		// buckets were always looked for in the provider's region, so keep
+		// doing that rather than failing.
+		if s3err, ok := err.(aws.APIError); ok && s3err.Code == "AccessDenied" {
+			log.Printf(
+				"[WARN] Not allowed to get the location of S3 bucket %s, assuming %s",
+				bucket, c.region)
+			c.setS3BucketRegion(bucket, c.region)
+			return c.region, nil
+		}
+
+		return "", fmt.Errorf("Error getting location of S3 bucket %s: %s", bucket, err)
+	}
+
+	region = s3LocationRegion(resp.LocationConstraint)
+	c.setS3BucketRegion(bucket, region)
+
+	return region, nil
+}
+
+// setS3BucketRegion records the region of a bucket, e.g. one that was just
+// created, so that it doesn't have to be looked up.
+func (c *AWSClient) setS3BucketRegion(bucket, region string) {
+	c.s3lock.Lock()
+	defer c.s3lock.Unlock()
+
+	if c.s3BucketRegions == nil {
+		c.s3BucketRegions = make(map[string]string)
+	}
+	c.s3BucketRegions[bucket] = region
+}
+
+// s3LocationRegion turns the LocationConstraint of a bucket into a region.
+// Buckets in us-east-1 have no location constraint, and very old buckets
+// in eu-west-1 report "EU".
+func s3LocationRegion(loc aws.StringValue) string {
+	if loc == nil || *loc == "" {
+		return "us-east-1"
+	}
+	if *loc == "EU" {
+		return "eu-west-1"
+	}
+
+	return *loc
+}
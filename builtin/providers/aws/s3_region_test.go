@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/aws-sdk-go/aws"
+	"github.com/hashicorp/aws-sdk-go/gen/s3"
+)
+
+func TestS3LocationRegion(t *testing.T) {
+	cases := []struct {
+		Location aws.StringValue
+		Region   string
+	}{
+		{nil, "us-east-1"},
+		{aws.String(""), "us-east-1"},
+		{aws.String("EU"), "eu-west-1"},
+		{aws.String("eu-west-1"), "eu-west-1"},
+		{aws.String("ap-northeast-1"), "ap-northeast-1"},
+	}
+
+	for _, tc := range cases {
+		if actual := s3LocationRegion(tc.Location); actual != tc.Region {
+			t.Fatalf("bad: %#v\n\n%s", tc.Location, actual)
+		}
+	}
+}
+
+func TestAWSClientS3ForBucket_cached(t *testing.T) {
+	c := &AWSClient{
+		region:        "us-east-1",
+		credsProvider: aws.Creds("foo", "bar", ""),
+		s3BucketRegions: map[string]string{
+			"local":   "us-east-1",
+			"remote":  "eu-west-1",
+			"remote2": "eu-west-1",
+		},
+	}
+
+	conn, err := c.S3ForBucket("local")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if conn != c.s3conn {
+		t.Fatal("should reuse the default connection")
+	}
+
+	conn, err = c.S3ForBucket("remote")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if conn == nil || conn == c.s3conn {
+		t.Fatal("should use a connection for the bucket's region")
+	}
+
+	// The connection to the other region is made once and shared
+	again, err := c.S3ForBucket("remote")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	other, err := c.S3ForBucket("remote2")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if again != conn || other != conn {
+		t.Fatal("should reuse the connection for the region")
+	}
+}
+
+func TestAWSClientS3ForBucket_location(t *testing.T) {
+	requests := 0
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if _, ok := r.URL.Query()["location"]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		<-block
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">eu-west-1</LocationConstraint>`)
+	}))
+	defer ts.Close()
+
+	client, err := endpointClient(ts.URL, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c := &AWSClient{
+		region:        "us-east-1",
+		credsProvider: aws.Creds("foo", "bar", ""),
+		httpClient:    client,
+		s3conn:        s3.New(aws.Creds("foo", "bar", ""), "us-east-1", client),
+	}
+	c.setS3BucketRegion("local", "us-east-1")
+
+	type result struct {
+		conn *s3.S3
+		err  error
+	}
+	remote := make(chan result)
+	go func() {
+		conn, err := c.S3ForBucket("remote")
+		remote <- result{conn, err}
+	}()
+
+	// Buckets with a known region aren't held up by the lookup
+	done := make(chan struct{})
+	go func() {
+		c.S3ForBucket("local")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked by the location lookup of another bucket")
+	}
+	close(block)
+
+	r := <-remote
+	if r.err != nil {
+		t.Fatalf("err: %s", r.err)
+	}
+	if r.conn == nil || r.conn == c.s3conn {
+		t.Fatal("should use a connection for the bucket's region")
+	}
+	if region, _ := c.s3BucketRegion("remote"); region != "eu-west-1" {
+		t.Fatalf("bad: %s", region)
+	}
+	if requests != 1 {
+		t.Fatalf("should look up the location once, got %d requests", requests)
+	}
+}
+
+func TestAWSClientS3ForBucket_accessDenied(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+	}))
+	defer ts.Close()
+
+	client, err := endpointClient(ts.URL, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	c := &AWSClient{
+		region:        "us-west-2",
+		credsProvider: aws.Creds("foo", "bar", ""),
+		httpClient:    client,
+		s3conn:        s3.New(aws.Creds("foo", "bar", ""), "us-west-2", client),
+	}
+
+	// Without s3:GetBucketLocation the default connection is used, as it
+	// was before buckets were located.
+	for i := 0; i < 2; i++ {
+		conn, err := c.S3ForBucket("bucket")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if conn != c.s3conn {
+			t.Fatal("should use the default connection")
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("should only try the lookup once, got %d requests", requests)
+	}
+}
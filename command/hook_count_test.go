@@ -9,3 +9,54 @@ import (
 func TestCountHook_impl(t *testing.T) {
 	var _ terraform.Hook = new(CountHook)
 }
+
+func TestCountHook_orphanDestroy(t *testing.T) {
+	h := new(CountHook)
+	p := testProvider()
+	p.ApplyFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState,
+		d *terraform.InstanceDiff) (*terraform.InstanceState, error) {
+		if d.Destroy {
+			return nil, nil
+		}
+
+		return s, nil
+	}
+
+	// test_instance.bar is no longer in the configuration
+	opts := testCtxConfig(p)
+	opts.Module = testModule(t, "apply")
+	opts.Hooks = []terraform.Hook{h}
+	opts.State = &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.bar": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := terraform.NewContext(opts)
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, ok := state.RootModule().Resources["test_instance.bar"]; ok {
+		t.Fatalf("bad: %s", state)
+	}
+	if h.Removed != 1 {
+		t.Fatalf("bad: %d", h.Removed)
+	}
+}
@@ -199,6 +199,24 @@ func (h *UiHook) PreRefresh(
 	return terraform.HookActionContinue, nil
 }
 
+func (h *UiHook) PostOrphanDestroy(
+	n *terraform.InstanceInfo,
+	err error) (terraform.HookAction, error) {
+	// Errors from the destroy itself are already shown by PostApply, so
+	// only a destroy that a hook vetoed is shown here.
+	veto, ok := err.(*terraform.OrphanDestroyVetoError)
+	if !ok {
+		return terraform.HookActionContinue, nil
+	}
+
+	h.once.Do(h.init)
+
+	h.ui.Output(h.Colorize.Color(fmt.Sprintf(
+		"[reset][bold][yellow]%s: Destroy skipped: %s[reset]",
+		n.HumanId(), veto.Err)))
+	return terraform.HookActionContinue, nil
+}
+
 func (h *UiHook) init() {
 	if h.Colorize == nil {
 		panic("colorize not given")
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/colorstring"
+)
+
+func TestUiHook_impl(t *testing.T) {
+	var _ terraform.Hook = new(UiHook)
+}
+
+func TestUiHookPostOrphanDestroy(t *testing.T) {
+	ui := new(cli.MockUi)
+	h := &UiHook{
+		Colorize: &colorstring.Colorize{
+			Colors:  colorstring.DefaultColors,
+			Disable: true,
+		},
+		Ui: ui,
+	}
+
+	info := &terraform.InstanceInfo{
+		Id:         "aws_instance.foo",
+		ModulePath: []string{"root", "child"},
+	}
+
+	// Destroy errors are left for PostApply to show
+	if _, err := h.PostOrphanDestroy(info, errors.New("destroy failed")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := h.PostOrphanDestroy(info, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ui.OutputWriter != nil && ui.OutputWriter.Len() > 0 {
+		t.Fatalf("bad: %q", ui.OutputWriter.String())
+	}
+
+	veto := &terraform.OrphanDestroyVetoError{Err: errors.New("protected")}
+	if _, err := h.PostOrphanDestroy(info, veto); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	expected := "module.child.aws_instance.foo: Destroy skipped: protected"
+	if actual != expected {
+		t.Fatalf("bad: %q", actual)
+	}
+}
@@ -1198,6 +1198,118 @@ func TestContext2Plan_orphan(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_orphanDiffDestroy(t *testing.T) {
+	m := testModule(t, "plan-orphan")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.baz": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The orphan must be a pure destroy, which is what makes the plan
+	// output render it as "- aws_instance.baz".
+	rd, ok := plan.Diff.RootModule().Resources["aws_instance.baz"]
+	if !ok {
+		t.Fatalf("bad: no diff for orphan\n\n%s", plan)
+	}
+	if !rd.Destroy || len(rd.Attributes) > 0 {
+		t.Fatalf("bad: %#v", rd)
+	}
+	if rd.ChangeType() != DiffDestroy {
+		t.Fatalf("bad: %#v", rd.ChangeType())
+	}
+}
+
+func TestContext2Plan_orphanProviderRemoved(t *testing.T) {
+	m := testModule(t, "plan-orphan-provider-removed")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	p.ConfigureReturnError = fmt.Errorf("region is required")
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	_, err := ctx.Plan()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "region is required") {
+		t.Fatalf("bad: %s", err)
+	}
+	if !strings.Contains(err.Error(), "provider.aws has no configuration") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestContext2Plan_missingProviderNoOrphans(t *testing.T) {
+	m := testModule(t, "plan-good")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	p.ConfigureReturnError = fmt.Errorf("region is required")
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	// The provider has no block, but isn't used by orphans, so nothing
+	// was removed that could be restored.
+	_, err := ctx.Plan()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "region is required") {
+		t.Fatalf("bad: %s", err)
+	}
+	if strings.Contains(err.Error(), "has no configuration") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
 func TestContext2Plan_state(t *testing.T) {
 	m := testModule(t, "plan-good")
 	p := testProvider("aws")
@@ -3091,6 +3203,103 @@ func TestContext2Apply_createBeforeDestroy(t *testing.T) {
 	}
 }
 
+func TestContext2Apply_createBeforeDestroyOrphan(t *testing.T) {
+	m := testModule(t, "apply-good-create-before")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	var lock sync.Mutex
+	var order []string
+	p.ApplyFn = func(
+		info *InstanceInfo,
+		s *InstanceState,
+		d *InstanceDiff) (*InstanceState, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if d.Destroy {
+			order = append(order, "destroy "+s.ID)
+		} else {
+			order = append(order, "create "+info.Id)
+		}
+
+		return testApplyFn(info, s, d)
+	}
+
+	// aws_instance.foo was create_before_destroy too, but it has been
+	// removed from the configuration. The lifecycle isn't in the state and
+	// there is nothing to replace it with, so it is simply destroyed.
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.bar": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+							Attributes: map[string]string{
+								"require_new": "abc",
+							},
+						},
+					},
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "foo",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: state,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	counts := make(map[string]int)
+	createIdx, destroyIdx := -1, -1
+	for i, op := range order {
+		counts[op]++
+		switch op {
+		case "create aws_instance.bar":
+			createIdx = i
+		case "destroy bar":
+			destroyIdx = i
+		}
+	}
+	expected := map[string]int{
+		"create aws_instance.bar": 1,
+		"destroy bar":             1,
+		"destroy foo":             1,
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("bad: %#v", order)
+	}
+	if createIdx > destroyIdx {
+		t.Fatalf("replacement should be created first: %#v", order)
+	}
+
+	actual := strings.TrimSpace(state.String())
+	expectedState := strings.TrimSpace(testTerraformApplyCreateBeforeStr)
+	if actual != expectedState {
+		t.Fatalf("bad: \n%s", actual)
+	}
+}
+
 func TestContext2Apply_createBeforeDestroyUpdate(t *testing.T) {
 	m := testModule(t, "apply-good-create-before-update")
 	p := testProvider("aws")
@@ -3429,6 +3638,385 @@ func TestContext2Apply_countDecreaseToOne(t *testing.T) {
 	}
 }
 
+func TestContext2Apply_countDecreaseToOneOrphans(t *testing.T) {
+	m := testModule(t, "apply-count-dec-one")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	var lock sync.Mutex
+	destroyed := make(map[string]int)
+	p.ApplyFn = func(
+		info *InstanceInfo,
+		s *InstanceState,
+		d *InstanceDiff) (*InstanceState, error) {
+		if d.Destroy {
+			lock.Lock()
+			defer lock.Unlock()
+			destroyed[info.Id]++
+		}
+
+		return testApplyFn(info, s, d)
+	}
+
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo.0": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+							Attributes: map[string]string{
+								"foo":  "foo",
+								"type": "aws_instance",
+							},
+						},
+					},
+					"aws_instance.foo.1": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "baz",
+						},
+					},
+					"aws_instance.foo.2": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "qux",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Each index beyond the new count must be destroyed exactly once
+	expectedDestroyed := map[string]int{
+		"aws_instance.foo.1": 1,
+		"aws_instance.foo.2": 1,
+	}
+	if !reflect.DeepEqual(destroyed, expectedDestroyed) {
+		t.Fatalf("bad: %#v", destroyed)
+	}
+
+	actual := strings.TrimSpace(state.String())
+	expected := strings.TrimSpace(testTerraformApplyCountDecToOneStr)
+	if actual != expected {
+		t.Fatalf("bad: \n%s", actual)
+	}
+}
+
+func TestContext2Apply_moduleOrphanRenamedResource(t *testing.T) {
+	m := testModule(t, "plan-modules-remove")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	var lock sync.Mutex
+	destroyed := make(map[string]int)
+	p.ApplyFn = func(
+		info *InstanceInfo,
+		s *InstanceState,
+		d *InstanceDiff) (*InstanceState, error) {
+		if d.Destroy {
+			lock.Lock()
+			defer lock.Unlock()
+			destroyed[s.ID]++
+		}
+
+		return testApplyFn(info, s, d)
+	}
+
+	// The removed module still has both the old and new name of a resource
+	// that was renamed in it just before the module itself was removed.
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.old": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "old",
+						},
+					},
+					"aws_instance.new": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "new",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Both resources are destroyed by the module orphan, once each
+	expectedDestroyed := map[string]int{
+		"old": 1,
+		"new": 1,
+	}
+	if !reflect.DeepEqual(destroyed, expectedDestroyed) {
+		t.Fatalf("bad: %#v", destroyed)
+	}
+
+	if mod := state.ModuleByPath([]string{"root", "child"}); mod != nil && len(mod.Resources) > 0 {
+		t.Fatalf("bad: %s", mod)
+	}
+}
+
+func TestContext2Apply_moduleOrphanNested(t *testing.T) {
+	for _, parentState := range []bool{true, false} {
+		m := testModule(t, "plan-modules-remove")
+		p := testProvider("aws")
+		p.DiffFn = testDiffFn
+
+		var lock sync.Mutex
+		var destroyed []string
+		p.ApplyFn = func(
+			info *InstanceInfo,
+			s *InstanceState,
+			d *InstanceDiff) (*InstanceState, error) {
+			if d.Destroy {
+				lock.Lock()
+				defer lock.Unlock()
+				destroyed = append(destroyed, s.ID)
+			}
+
+			return testApplyFn(info, s, d)
+		}
+
+		// The removed module "parent" contains the module "child". Without
+		// resources or outputs, "parent" doesn't have a state of its own.
+		s := &State{
+			Modules: []*ModuleState{
+				&ModuleState{
+					Path: []string{"root", "parent", "child"},
+					Resources: map[string]*ResourceState{
+						"aws_instance.foo": &ResourceState{
+							Type: "aws_instance",
+							Primary: &InstanceState{
+								ID: "child",
+							},
+						},
+					},
+				},
+			},
+		}
+		if parentState {
+			s.Modules = append(s.Modules, &ModuleState{
+				Path: []string{"root", "parent"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "parent",
+						},
+					},
+				},
+			})
+		}
+
+		ctx := testContext2(t, &ContextOpts{
+			Module: m,
+			Providers: map[string]ResourceProviderFactory{
+				"aws": testProviderFuncFixed(p),
+			},
+			State: s,
+		})
+
+		if _, err := ctx.Plan(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		state, err := ctx.Apply()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		// The inner-most module is destroyed first, and everything once
+		expected := []string{"child"}
+		if parentState {
+			expected = append(expected, "parent")
+		}
+		if !reflect.DeepEqual(destroyed, expected) {
+			t.Fatalf("bad: %#v", destroyed)
+		}
+
+		for _, path := range [][]string{
+			[]string{"root", "parent"},
+			[]string{"root", "parent", "child"},
+		} {
+			if mod := state.ModuleByPath(path); mod != nil && len(mod.Resources) > 0 {
+				t.Fatalf("bad: %s", mod)
+			}
+		}
+	}
+}
+
+func TestContext2Apply_orphanDestroyCount(t *testing.T) {
+	m := testModule(t, "plan-orphan")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.baz": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The orphan is in the plan's destroy tally
+	var destroy []string
+	for k, rd := range plan.Diff.RootModule().Resources {
+		if rd.ChangeType() == DiffDestroy {
+			destroy = append(destroy, k)
+		}
+	}
+	if !reflect.DeepEqual(destroy, []string{"aws_instance.baz"}) {
+		t.Fatalf("bad: %#v\n\n%s", destroy, plan)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// ...and is destroyed on apply. The CLI's count of it is tested with
+	// command.CountHook.
+	if _, ok := state.RootModule().Resources["aws_instance.baz"]; ok {
+		t.Fatalf("bad: %s", state)
+	}
+}
+
+func TestContext2Apply_orphanDestroyHookVeto(t *testing.T) {
+	m := testModule(t, "plan-modules-remove")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	h := &HookVetoOrphanDestroy{
+		Veto: map[string]struct{}{"aws_instance.baz": struct{}{}},
+	}
+
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.bar": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
+					},
+					"aws_instance.baz": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "baz",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Hooks:  []Hook{h},
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	sort.Strings(h.Pre)
+	if !reflect.DeepEqual(h.Pre, []string{"aws_instance.bar", "aws_instance.baz"}) {
+		t.Fatalf("bad: %#v", h.Pre)
+	}
+	sort.Strings(h.Post)
+	if !reflect.DeepEqual(h.Post, []string{"aws_instance.bar", "aws_instance.baz"}) {
+		t.Fatalf("bad: %#v", h.Post)
+	}
+
+	// The veto is reported to the post hook, the destroy is not
+	if len(h.PostErrs) != 1 {
+		t.Fatalf("bad: %#v", h.PostErrs)
+	}
+	if _, ok := h.PostErrs["aws_instance.baz"].(*OrphanDestroyVetoError); !ok {
+		t.Fatalf("bad: %#v", h.PostErrs)
+	}
+
+	// The vetoed orphan is left alone, the other one is destroyed
+	mod := state.RootModule()
+	if _, ok := mod.Resources["aws_instance.bar"]; ok {
+		t.Fatalf("bad: %s", state)
+	}
+	if rs, ok := mod.Resources["aws_instance.baz"]; !ok || rs.Primary.ID != "baz" {
+		t.Fatalf("bad: %s", state)
+	}
+}
+
 // https://github.com/PeoplePerHour/terraform/pull/11
 //
 // This tests a case where both a "resource" and "resource.0" are in
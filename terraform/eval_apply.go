@@ -127,6 +127,64 @@ func (n *EvalApplyPost) Eval(ctx EvalContext) (interface{}, error) {
 	return nil, *n.Error
 }
 
+// EvalOrphanDestroyPre is an EvalNode implementation that calls the
+// pre-destroy hook for an orphan. If a hook returns an error, only this
+// orphan's destroy is skipped: the veto is reported to the post-destroy
+// hook and the rest of the apply carries on. If a hook halts, the destroy
+// is skipped by exiting early.
+type EvalOrphanDestroyPre struct {
+	Info *InstanceInfo
+	Diff **InstanceDiff
+}
+
+func (n *EvalOrphanDestroyPre) Eval(ctx EvalContext) (interface{}, error) {
+	diff := *n.Diff
+	if diff == nil || !diff.Destroy {
+		return nil, nil
+	}
+
+	err := ctx.Hook(func(h Hook) (HookAction, error) {
+		return h.PreOrphanDestroy(n.Info, diff)
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if _, ok := err.(EvalEarlyExitError); ok {
+		return nil, err
+	}
+
+	log.Printf("[WARN] %s: destroy vetoed by hook: %s", n.Info.Id, err)
+	veto := &OrphanDestroyVetoError{Err: err}
+	if err := ctx.Hook(func(h Hook) (HookAction, error) {
+		return h.PostOrphanDestroy(n.Info, veto)
+	}); err != nil {
+		return nil, err
+	}
+
+	return nil, EvalEarlyExitError{}
+}
+
+// EvalOrphanDestroyPost is an EvalNode implementation that calls the
+// post-destroy hook for an orphan. The apply error itself is left for the
+// EvalApplyPost that follows it to return.
+type EvalOrphanDestroyPost struct {
+	Info  *InstanceInfo
+	Diff  **InstanceDiff
+	Error *error
+}
+
+func (n *EvalOrphanDestroyPost) Eval(ctx EvalContext) (interface{}, error) {
+	diff := *n.Diff
+	if diff == nil || !diff.Destroy {
+		return nil, nil
+	}
+
+	// Call post-destroy hook
+	return nil, ctx.Hook(func(h Hook) (HookAction, error) {
+		return h.PostOrphanDestroy(n.Info, *n.Error)
+	})
+}
+
 // EvalApplyProvisioners is an EvalNode implementation that executes
 // the provisioners for a resource.
 //
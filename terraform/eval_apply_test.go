@@ -0,0 +1,112 @@
+package terraform
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvalOrphanDestroyPre(t *testing.T) {
+	hook := new(MockHook)
+	ctx := &MockEvalContext{HookHook: hook}
+
+	info := &InstanceInfo{Id: "aws_instance.foo"}
+	diff := &InstanceDiff{Destroy: true}
+	n := &EvalOrphanDestroyPre{Info: info, Diff: &diff}
+	if _, err := n.Eval(ctx); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !hook.PreOrphanDestroyCalled {
+		t.Fatal("should be called")
+	}
+	if hook.PreOrphanDestroyInfo != info {
+		t.Fatalf("bad: %#v", hook.PreOrphanDestroyInfo)
+	}
+	if hook.PreOrphanDestroyDiff != diff {
+		t.Fatalf("bad: %#v", hook.PreOrphanDestroyDiff)
+	}
+}
+
+func TestEvalOrphanDestroyPre_veto(t *testing.T) {
+	vetoErr := errors.New("protected")
+	hook := &MockHook{PreOrphanDestroyError: vetoErr}
+	ctx := &MockEvalContext{HookHook: hook}
+
+	diff := &InstanceDiff{Destroy: true}
+	n := &EvalOrphanDestroyPre{
+		Info: &InstanceInfo{Id: "aws_instance.foo"},
+		Diff: &diff,
+	}
+	_, err := n.Eval(ctx)
+	if _, ok := err.(EvalEarlyExitError); !ok {
+		t.Fatalf("should skip only this destroy, got: %#v", err)
+	}
+
+	if !hook.PostOrphanDestroyCalled {
+		t.Fatal("should report the veto")
+	}
+	veto, ok := hook.PostOrphanDestroyError.(*OrphanDestroyVetoError)
+	if !ok || veto.Err != vetoErr {
+		t.Fatalf("bad: %#v", hook.PostOrphanDestroyError)
+	}
+}
+
+func TestEvalOrphanDestroyPre_halt(t *testing.T) {
+	hook := new(MockHook)
+	ctx := &MockEvalContext{HookHook: hook, HookError: EvalEarlyExitError{}}
+
+	diff := &InstanceDiff{Destroy: true}
+	n := &EvalOrphanDestroyPre{
+		Info: &InstanceInfo{Id: "aws_instance.foo"},
+		Diff: &diff,
+	}
+	_, err := n.Eval(ctx)
+	if _, ok := err.(EvalEarlyExitError); !ok {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	if hook.PostOrphanDestroyCalled {
+		t.Fatal("halting shouldn't be reported as a veto")
+	}
+}
+
+func TestEvalOrphanDestroyPre_noDestroy(t *testing.T) {
+	hook := new(MockHook)
+	ctx := &MockEvalContext{HookHook: hook}
+
+	var diff *InstanceDiff
+	n := &EvalOrphanDestroyPre{
+		Info: &InstanceInfo{Id: "aws_instance.foo"},
+		Diff: &diff,
+	}
+	if _, err := n.Eval(ctx); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if ctx.HookCalled {
+		t.Fatal("should not be called")
+	}
+}
+
+func TestEvalOrphanDestroyPost(t *testing.T) {
+	hook := new(MockHook)
+	ctx := &MockEvalContext{HookHook: hook}
+
+	applyErr := errors.New("destroy failed")
+	diff := &InstanceDiff{Destroy: true}
+	n := &EvalOrphanDestroyPost{
+		Info:  &InstanceInfo{Id: "aws_instance.foo"},
+		Diff:  &diff,
+		Error: &applyErr,
+	}
+	if _, err := n.Eval(ctx); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !hook.PostOrphanDestroyCalled {
+		t.Fatal("should be called")
+	}
+	if hook.PostOrphanDestroyError != applyErr {
+		t.Fatalf("bad: %#v", hook.PostOrphanDestroyError)
+	}
+}
@@ -145,7 +145,6 @@ type EvalDiffDestroy struct {
 	Output **InstanceDiff
 }
 
-// TODO: test
 func (n *EvalDiffDestroy) Eval(ctx EvalContext) (interface{}, error) {
 	state := *n.State
 
@@ -5,6 +5,55 @@ import (
 	"testing"
 )
 
+func TestEvalDiffDestroy(t *testing.T) {
+	hook := new(MockHook)
+	ctx := &MockEvalContext{HookHook: hook}
+
+	var output *InstanceDiff
+	state := &InstanceState{ID: "foo"}
+	n := &EvalDiffDestroy{
+		Info:   &InstanceInfo{Id: "aws_instance.foo"},
+		State:  &state,
+		Output: &output,
+	}
+	if _, err := n.Eval(ctx); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &InstanceDiff{Destroy: true}
+	if !reflect.DeepEqual(output, expected) {
+		t.Fatalf("bad: %#v", output)
+	}
+	if !hook.PreDiffCalled || !hook.PostDiffCalled {
+		t.Fatal("hooks should be called")
+	}
+	if !reflect.DeepEqual(hook.PostDiffDiff, expected) {
+		t.Fatalf("bad: %#v", hook.PostDiffDiff)
+	}
+}
+
+func TestEvalDiffDestroy_noState(t *testing.T) {
+	ctx := new(MockEvalContext)
+
+	var output *InstanceDiff
+	var state *InstanceState
+	n := &EvalDiffDestroy{
+		Info:   &InstanceInfo{Id: "aws_instance.foo"},
+		State:  &state,
+		Output: &output,
+	}
+	if _, err := n.Eval(ctx); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if output != nil {
+		t.Fatalf("bad: %#v", output)
+	}
+	if ctx.HookCalled {
+		t.Fatal("hook should not be called")
+	}
+}
+
 func TestEvalFilterDiff(t *testing.T) {
 	ctx := new(MockEvalContext)
 
@@ -49,6 +49,46 @@ func (n *EvalConfigProvider) Eval(ctx EvalContext) (interface{}, error) {
 	return nil, ctx.ConfigureProvider(n.Provider, *n.Config)
 }
 
+// EvalMissingProvider is an EvalNode implementation that evaluates the
+// tree of a provider that has no configuration block and is only used by
+// orphans. It's a proxy to that tree that adds a hint to any error: the
+// most likely cause is that the provider's configuration was removed along
+// with resources that are still in the state, which can't be destroyed
+// without it.
+type EvalMissingProvider struct {
+	Name string
+	Node EvalNode
+}
+
+func (n *EvalMissingProvider) Eval(ctx EvalContext) (interface{}, error) {
+	result, err := EvalRaw(n.Node, ctx)
+	if err == nil {
+		return result, nil
+	}
+
+	hint := fmt.Errorf(
+		"provider.%s has no configuration. If it was removed at the same "+
+			"time as resources that are still in the state, restore it "+
+			"until those resources have been destroyed.", n.Name)
+
+	switch e := err.(type) {
+	case EvalEarlyExitError:
+		return result, err
+	case *EvalValidateError:
+		if len(e.Errors) > 0 {
+			e.Errors = append(e.Errors, hint)
+		}
+		return result, e
+	default:
+		return result, fmt.Errorf("%s\n\n%s", err, hint)
+	}
+}
+
+// EvalNodeFilterable impl.
+func (n *EvalMissingProvider) Filter(fn EvalNodeFilterFunc) {
+	n.Node = EvalFilter(n.Node, fn)
+}
+
 // EvalInitProvider is an EvalNode implementation that initializes a provider
 // and returns nothing. The provider can be retrieved again with the
 // EvalGetProvider node.
@@ -1,7 +1,9 @@
 package terraform
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -112,6 +114,48 @@ func TestEvalInitProvider(t *testing.T) {
 	}
 }
 
+func TestEvalMissingProvider_impl(t *testing.T) {
+	var _ EvalNodeFilterable = new(EvalMissingProvider)
+}
+
+func TestEvalMissingProvider(t *testing.T) {
+	var provider ResourceProvider = &MockResourceProvider{}
+	config := testResourceConfig(t, map[string]interface{}{})
+	n := &EvalMissingProvider{
+		Name: "foo",
+		Node: &EvalValidateProvider{Provider: &provider, Config: &config},
+	}
+
+	ctx := &MockEvalContext{}
+	if _, err := n.Eval(ctx); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestEvalMissingProvider_validateError(t *testing.T) {
+	var provider ResourceProvider = &MockResourceProvider{
+		ValidateReturnErrors: []error{errors.New("bad")},
+	}
+	config := testResourceConfig(t, map[string]interface{}{})
+	n := &EvalMissingProvider{
+		Name: "foo",
+		Node: &EvalValidateProvider{Provider: &provider, Config: &config},
+	}
+
+	ctx := &MockEvalContext{}
+	_, err := n.Eval(ctx)
+	verr, ok := err.(*EvalValidateError)
+	if !ok {
+		t.Fatalf("bad: %#v", err)
+	}
+	if len(verr.Errors) != 2 {
+		t.Fatalf("bad: %#v", verr.Errors)
+	}
+	if !strings.Contains(verr.Errors[1].Error(), "provider.foo has no configuration") {
+		t.Fatalf("bad: %s", verr.Errors[1])
+	}
+}
+
 func TestEvalGetProvider_impl(t *testing.T) {
 	var _ EvalNode = new(EvalGetProvider)
 }
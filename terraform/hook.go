@@ -1,5 +1,7 @@
 package terraform
 
+import "fmt"
+
 // HookAction is an enum of actions that can be taken as a result of a hook
 // callback. This allows you to modify the behavior of Terraform at runtime.
 type HookAction byte
@@ -13,6 +15,16 @@ const (
 	HookActionHalt
 )
 
+// OrphanDestroyVetoError is the error PostOrphanDestroy is called with when
+// a PreOrphanDestroy hook vetoed the destroy by returning Err.
+type OrphanDestroyVetoError struct {
+	Err error
+}
+
+func (e *OrphanDestroyVetoError) Error() string {
+	return fmt.Sprintf("destroy vetoed: %s", e.Err)
+}
+
 // Hook is the interface that must be implemented to hook into various
 // parts of Terraform, allowing you to inspect or change behavior at runtime.
 //
@@ -50,6 +62,22 @@ type Hook interface {
 	PreRefresh(*InstanceInfo, *InstanceState) (HookAction, error)
 	PostRefresh(*InstanceInfo, *InstanceState) (HookAction, error)
 
+	// PreOrphanDestroy and PostOrphanDestroy are called before and after
+	// a resource that is no longer in the configuration is destroyed. This
+	// includes the resources of a module that was removed.
+	//
+	// Returning an error from PreOrphanDestroy vetoes the destroy of that
+	// resource only: it is left in the state, the rest of the apply carries
+	// on, and PostOrphanDestroy is called with an *OrphanDestroyVetoError.
+	// Otherwise the error argument in PostOrphanDestroy is the error, if
+	// any, that was returned from the provider Apply call itself.
+	//
+	// Returning HookActionHalt from PreOrphanDestroy also skips the
+	// destroy, but as with every other hook it means Terraform is stopping,
+	// e.g. because it was interrupted, so it isn't reported.
+	PreOrphanDestroy(*InstanceInfo, *InstanceDiff) (HookAction, error)
+	PostOrphanDestroy(*InstanceInfo, error) (HookAction, error)
+
 	// PostStateUpdate is called after the state is updated.
 	PostStateUpdate(*State) (HookAction, error)
 }
@@ -103,6 +131,14 @@ func (*NilHook) PostRefresh(*InstanceInfo, *InstanceState) (HookAction, error) {
 	return HookActionContinue, nil
 }
 
+func (*NilHook) PreOrphanDestroy(*InstanceInfo, *InstanceDiff) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*NilHook) PostOrphanDestroy(*InstanceInfo, error) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
 func (*NilHook) PostStateUpdate(*State) (HookAction, error) {
 	return HookActionContinue, nil
 }
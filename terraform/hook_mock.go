@@ -70,6 +70,18 @@ type MockHook struct {
 	PreRefreshReturn HookAction
 	PreRefreshError  error
 
+	PreOrphanDestroyCalled bool
+	PreOrphanDestroyInfo   *InstanceInfo
+	PreOrphanDestroyDiff   *InstanceDiff
+	PreOrphanDestroyReturn HookAction
+	PreOrphanDestroyError  error
+
+	PostOrphanDestroyCalled      bool
+	PostOrphanDestroyInfo        *InstanceInfo
+	PostOrphanDestroyError       error
+	PostOrphanDestroyReturn      HookAction
+	PostOrphanDestroyReturnError error
+
 	PostStateUpdateCalled bool
 	PostStateUpdateState  *State
 	PostStateUpdateReturn HookAction
@@ -158,6 +170,20 @@ func (h *MockHook) PostRefresh(n *InstanceInfo, s *InstanceState) (HookAction, e
 	return h.PostRefreshReturn, h.PostRefreshError
 }
 
+func (h *MockHook) PreOrphanDestroy(n *InstanceInfo, d *InstanceDiff) (HookAction, error) {
+	h.PreOrphanDestroyCalled = true
+	h.PreOrphanDestroyInfo = n
+	h.PreOrphanDestroyDiff = d
+	return h.PreOrphanDestroyReturn, h.PreOrphanDestroyError
+}
+
+func (h *MockHook) PostOrphanDestroy(n *InstanceInfo, e error) (HookAction, error) {
+	h.PostOrphanDestroyCalled = true
+	h.PostOrphanDestroyInfo = n
+	h.PostOrphanDestroyError = e
+	return h.PostOrphanDestroyReturn, h.PostOrphanDestroyReturnError
+}
+
 func (h *MockHook) PostStateUpdate(s *State) (HookAction, error) {
 	h.PostStateUpdateCalled = true
 	h.PostStateUpdateState = s
@@ -53,6 +53,14 @@ func (h *stopHook) PostRefresh(*InstanceInfo, *InstanceState) (HookAction, error
 	return h.hook()
 }
 
+func (h *stopHook) PreOrphanDestroy(*InstanceInfo, *InstanceDiff) (HookAction, error) {
+	return h.hook()
+}
+
+func (h *stopHook) PostOrphanDestroy(*InstanceInfo, error) (HookAction, error) {
+	return h.hook()
+}
+
 func (h *stopHook) PostStateUpdate(*State) (HookAction, error) {
 	return h.hook()
 }
@@ -111,15 +111,32 @@ func (s *State) ModuleOrphans(path []string, c *config.Config) [][]string {
 		}
 	}
 
-	// Go over the direct children and find any that aren't in our
-	// keys.
+	// Go over all the modules below us and find the direct children that
+	// aren't in our keys. A child doesn't need a state of its own to be an
+	// orphan: a module without resources or outputs never gets one, but the
+	// modules nested in it still need to be found through it.
 	var orphans [][]string
-	for _, m := range s.Children(path) {
-		if _, ok := childrenKeys[m.Path[len(m.Path)-1]]; ok {
+	seen := make(map[string]struct{})
+	for _, m := range s.Modules {
+		if len(m.Path) <= len(path) {
+			continue
+		}
+		if !reflect.DeepEqual(path, m.Path[:len(path)]) {
+			continue
+		}
+
+		name := m.Path[len(path)]
+		if _, ok := childrenKeys[name]; ok {
+			continue
+		}
+		if _, ok := seen[name]; ok {
 			continue
 		}
+		seen[name] = struct{}{}
 
-		orphans = append(orphans, m.Path)
+		child := make([]string, len(path)+1)
+		copy(child, m.Path)
+		orphans = append(orphans, child)
 	}
 
 	return orphans
@@ -111,6 +111,37 @@ func TestStateModuleOrphans_nilConfig(t *testing.T) {
 	}
 }
 
+func TestStateModuleOrphans_deep(t *testing.T) {
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: RootModulePath,
+			},
+			&ModuleState{
+				Path: []string{RootModuleName, "foo", "baz"},
+			},
+			&ModuleState{
+				Path: []string{RootModuleName, "foo", "qux"},
+			},
+			&ModuleState{
+				Path: []string{RootModuleName, "bar", "baz"},
+			},
+		},
+	}
+
+	// "foo" has no state of its own but is still an orphan, once, and the
+	// modules in "bar" aren't orphans of the root module.
+	config := testModule(t, "state-module-orphans").Config()
+	actual := state.ModuleOrphans(RootModulePath, config)
+	expected := [][]string{
+		[]string{RootModuleName, "foo"},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
 func TestStateEqual(t *testing.T) {
 	cases := []struct {
 		Result   bool
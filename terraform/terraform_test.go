@@ -120,6 +120,48 @@ func (h *HookRecordApplyOrder) PreApply(
 	return HookActionContinue, nil
 }
 
+// HookVetoOrphanDestroy is a test hook that vetoes destroying the orphans
+// in Veto by returning an error, and records the orphans it was called for.
+type HookVetoOrphanDestroy struct {
+	NilHook
+
+	Veto map[string]struct{}
+
+	Pre      []string
+	Post     []string
+	PostErrs map[string]error
+
+	l sync.Mutex
+}
+
+func (h *HookVetoOrphanDestroy) PreOrphanDestroy(
+	info *InstanceInfo, d *InstanceDiff) (HookAction, error) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	h.Pre = append(h.Pre, info.Id)
+	if _, ok := h.Veto[info.Id]; ok {
+		return HookActionContinue, fmt.Errorf("%s is protected", info.Id)
+	}
+
+	return HookActionContinue, nil
+}
+
+func (h *HookVetoOrphanDestroy) PostOrphanDestroy(
+	info *InstanceInfo, err error) (HookAction, error) {
+	h.l.Lock()
+	defer h.l.Unlock()
+
+	h.Post = append(h.Post, info.Id)
+	if err != nil {
+		if h.PostErrs == nil {
+			h.PostErrs = make(map[string]error)
+		}
+		h.PostErrs[info.Id] = err
+	}
+	return HookActionContinue, nil
+}
+
 // Below are all the constant strings that are the expected output for
 // various tests.
 
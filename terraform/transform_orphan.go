@@ -3,6 +3,7 @@ package terraform
 import (
 	"fmt"
 	"log"
+	"sort"
 
 	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/config/module"
@@ -74,35 +75,52 @@ func (t *OrphanTransformer) Transform(g *Graph) error {
 			state = state.View(t.View)
 		}
 
-		// Go over each resource orphan and add it to the graph.
+		// Go over each resource orphan and add it to the graph. The orphans
+		// are keyed by their full state key, including the index for
+		// counted resources (i.e. "aws_instance.foo.2"), so they're sorted
+		// to keep the graph stable.
 		resourceOrphans := state.Orphans(config)
-		resourceVertexes = make([]dag.Vertex, len(resourceOrphans))
-		for i, k := range resourceOrphans {
+		sort.Strings(resourceOrphans)
+		resourceVertexes = make([]dag.Vertex, 0, len(resourceOrphans))
+		for _, k := range resourceOrphans {
 			// If this orphan is represented by some other node somehow,
-			// then ignore it.
+			// then ignore it. This happens when a count decreases: the
+			// remaining indexes are represented by the count-expanded
+			// nodes and only the rest are real orphans.
 			if _, ok := resourceRep[k]; ok {
 				continue
 			}
 
-			rs := state.Resources[k]
+			// Mark it as represented so it is never added twice.
+			resourceRep[k] = struct{}{}
 
-			resourceVertexes[i] = g.Add(&graphNodeOrphanResource{
-				ResourceName: k,
-				ResourceType: rs.Type,
-				dependentOn:  rs.Dependencies,
-			})
+			rs := state.Resources[k]
+			n, err := newOrphanResourceNode(k, rs.Type, rs.Dependencies)
+			if err != nil {
+				return err
+			}
+			resourceVertexes = append(resourceVertexes, g.Add(n))
 		}
 	}
 
 	// Go over each module orphan and add it to the graph. We store the
 	// vertexes and states outside so that we can connect dependencies later.
+	// Resources inside an orphaned module are never resource orphans here,
+	// since those only come from the state at our own path: they are all
+	// destroyed by the module's subgraph when it is expanded.
 	moduleOrphans := t.State.ModuleOrphans(g.Path, config)
 	moduleVertexes := make([]dag.Vertex, len(moduleOrphans))
 	for i, path := range moduleOrphans {
-		moduleVertexes[i] = g.Add(&graphNodeOrphanModule{
-			Path:        path,
-			dependentOn: t.State.ModuleByPath(path).Dependencies,
-		})
+		var deps []string
+		if s := t.State.ModuleByPath(path); s != nil {
+			deps = s.Dependencies
+		}
+
+		n, err := newOrphanModuleNode(path, deps)
+		if err != nil {
+			return err
+		}
+		moduleVertexes[i] = g.Add(n)
 	}
 
 	// Now do the dependencies. We do this _after_ adding all the orphan
@@ -119,6 +137,26 @@ func (t *OrphanTransformer) Transform(g *Graph) error {
 		g.ConnectDependent(v)
 	}
 
+	// If this whole module is being torn down as an orphan itself, there is
+	// no configuration left to order its destroys by. Modules nested in it
+	// are usually handed its values, so they are destroyed first, inner-most
+	// first as each of them is expanded the same way. Dependencies recorded
+	// in the state win, so this never introduces a cycle.
+	if config == nil && len(g.Path) > 1 {
+		for _, mv := range moduleVertexes {
+			ancestors, err := g.Ancestors(mv)
+			if err != nil {
+				return err
+			}
+
+			for _, rv := range resourceVertexes {
+				if !ancestors.Include(rv) {
+					g.Connect(dag.BasicEdge(rv, mv))
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -129,6 +167,20 @@ type graphNodeOrphanModule struct {
 	dependentOn []string
 }
 
+// newOrphanModuleNode returns the node for the orphaned module at path.
+// The path must be a full module path below the root module, since the
+// root module itself can never be an orphan.
+func newOrphanModuleNode(path []string, deps []string) (*graphNodeOrphanModule, error) {
+	if len(path) < 2 {
+		return nil, fmt.Errorf("invalid orphan module path: %v", path)
+	}
+
+	return &graphNodeOrphanModule{
+		Path:        path,
+		dependentOn: deps,
+	}, nil
+}
+
 func (n *graphNodeOrphanModule) DependableName() []string {
 	return []string{n.dependableName()}
 }
@@ -166,6 +218,23 @@ type graphNodeOrphanResource struct {
 	dependentOn []string
 }
 
+// newOrphanResourceNode returns the node for the orphaned resource with
+// the given state key, such as "aws_instance.foo.1", and type.
+func newOrphanResourceNode(name, typ string, deps []string) (*graphNodeOrphanResource, error) {
+	if name == "" {
+		return nil, fmt.Errorf("orphan resource must have a name")
+	}
+	if typ == "" {
+		return nil, fmt.Errorf("orphan resource %s must have a type", name)
+	}
+
+	return &graphNodeOrphanResource{
+		ResourceName: name,
+		ResourceType: typ,
+		dependentOn:  deps,
+	}, nil
+}
+
 func (n *graphNodeOrphanResource) DependableName() []string {
 	return []string{n.dependableName()}
 }
@@ -222,7 +291,9 @@ func (n *graphNodeOrphanResource) EvalTree() EvalNode {
 		},
 	})
 
-	// Diff the resource
+	// Diff the resource. An orphan is always a pure destroy: there is no
+	// configuration to create a replacement from, so lifecycle settings
+	// such as create_before_destroy don't apply to it.
 	var diff *InstanceDiff
 	seq.Nodes = append(seq.Nodes, &EvalOpFilter{
 		Ops: []walkOperation{walkPlan, walkPlanDestroy},
@@ -246,6 +317,7 @@ func (n *graphNodeOrphanResource) EvalTree() EvalNode {
 	})
 
 	// Apply
+	var err error
 	seq.Nodes = append(seq.Nodes, &EvalOpFilter{
 		Ops: []walkOperation{walkApply},
 		Node: &EvalSequence{
@@ -254,6 +326,10 @@ func (n *graphNodeOrphanResource) EvalTree() EvalNode {
 					Name: n.ResourceName,
 					Diff: &diff,
 				},
+				&EvalOrphanDestroyPre{
+					Info: info,
+					Diff: &diff,
+				},
 				&EvalGetProvider{
 					Name:   n.ProvidedBy()[0],
 					Output: &provider,
@@ -268,6 +344,7 @@ func (n *graphNodeOrphanResource) EvalTree() EvalNode {
 					Diff:     &diff,
 					Provider: &provider,
 					Output:   &state,
+					Error:    &err,
 				},
 				&EvalWriteState{
 					Name:         n.ResourceName,
@@ -275,6 +352,16 @@ func (n *graphNodeOrphanResource) EvalTree() EvalNode {
 					Dependencies: n.DependentOn(),
 					State:        &state,
 				},
+				&EvalOrphanDestroyPost{
+					Info:  info,
+					Diff:  &diff,
+					Error: &err,
+				},
+				&EvalApplyPost{
+					Info:  info,
+					State: &state,
+					Error: &err,
+				},
 				&EvalUpdateStateHook{},
 			},
 		},
@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -329,6 +330,70 @@ func TestGraphNodeOrphanModule_impl(t *testing.T) {
 	var _ GraphNodeExpandable = new(graphNodeOrphanModule)
 }
 
+func TestNewOrphanModuleNode(t *testing.T) {
+	n, err := newOrphanModuleNode([]string{"root", "foo", "bar"}, []string{"aws_instance.foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if v := n.Name(); v != "module.bar (orphan)" {
+		t.Fatalf("bad: %s", v)
+	}
+	if v := n.DependableName(); !reflect.DeepEqual(v, []string{"module.bar"}) {
+		t.Fatalf("bad: %#v", v)
+	}
+	if v := n.DependentOn(); !reflect.DeepEqual(v, []string{"aws_instance.foo"}) {
+		t.Fatalf("bad: %#v", v)
+	}
+}
+
+func TestNewOrphanModuleNode_invalid(t *testing.T) {
+	cases := [][]string{
+		nil,
+		[]string{},
+		RootModulePath,
+	}
+
+	for _, path := range cases {
+		if _, err := newOrphanModuleNode(path, nil); err == nil {
+			t.Fatalf("should error: %#v", path)
+		}
+	}
+}
+
+func TestNewOrphanResourceNode(t *testing.T) {
+	n, err := newOrphanResourceNode("aws_instance.foo.1", "aws_instance", []string{"aws_vpc.bar"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if v := n.Name(); v != "aws_instance.foo.1 (orphan)" {
+		t.Fatalf("bad: %s", v)
+	}
+	if v := n.DependableName(); !reflect.DeepEqual(v, []string{"aws_instance.foo.1"}) {
+		t.Fatalf("bad: %#v", v)
+	}
+	if v := n.DependentOn(); !reflect.DeepEqual(v, []string{"aws_vpc.bar"}) {
+		t.Fatalf("bad: %#v", v)
+	}
+}
+
+func TestNewOrphanResourceNode_invalid(t *testing.T) {
+	cases := []struct {
+		Name string
+		Type string
+	}{
+		{"", "aws_instance"},
+		{"aws_instance.foo", ""},
+	}
+
+	for _, tc := range cases {
+		if _, err := newOrphanResourceNode(tc.Name, tc.Type, nil); err == nil {
+			t.Fatalf("should error: %#v", tc)
+		}
+	}
+}
+
 func TestGraphNodeOrphanResource_impl(t *testing.T) {
 	var _ dag.Vertex = new(graphNodeOrphanResource)
 	var _ dag.NamedVertex = new(graphNodeOrphanResource)
@@ -59,6 +59,25 @@ type MissingProviderTransformer struct {
 }
 
 func (t *MissingProviderTransformer) Transform(g *Graph) error {
+	// Find the providers that are only used by orphans. If such a provider
+	// has no configuration, it was likely removed along with the resources.
+	orphanOnly := make(map[string]bool)
+	for _, v := range g.Vertices() {
+		pv, ok := v.(GraphNodeProviderConsumer)
+		if !ok {
+			continue
+		}
+
+		_, orphan := v.(*graphNodeOrphanResource)
+		for _, p := range pv.ProvidedBy() {
+			if only, ok := orphanOnly[p]; ok {
+				orphanOnly[p] = only && orphan
+			} else {
+				orphanOnly[p] = orphan
+			}
+		}
+	}
+
 	m := providerVertexMap(g)
 	for _, p := range t.Providers {
 		if _, ok := m[p]; ok {
@@ -67,7 +86,10 @@ func (t *MissingProviderTransformer) Transform(g *Graph) error {
 		}
 
 		// Add our own missing provider node to the graph
-		g.Add(&graphNodeMissingProvider{ProviderNameValue: p})
+		g.Add(&graphNodeMissingProvider{
+			ProviderNameValue: p,
+			OrphanOnly:        orphanOnly[p],
+		})
 	}
 
 	return nil
@@ -96,6 +118,10 @@ func (t *PruneProviderTransformer) Transform(g *Graph) error {
 
 type graphNodeMissingProvider struct {
 	ProviderNameValue string
+
+	// OrphanOnly is true if the provider is only used by orphans, in which
+	// case errors hint that its configuration should be restored.
+	OrphanOnly bool
 }
 
 func (n *graphNodeMissingProvider) Name() string {
@@ -104,7 +130,15 @@ func (n *graphNodeMissingProvider) Name() string {
 
 // GraphNodeEvalable impl.
 func (n *graphNodeMissingProvider) EvalTree() EvalNode {
-	return ProviderEvalTree(n.ProviderNameValue, nil)
+	tree := ProviderEvalTree(n.ProviderNameValue, nil)
+	if !n.OrphanOnly {
+		return tree
+	}
+
+	return &EvalMissingProvider{
+		Name: n.ProviderNameValue,
+		Node: tree,
+	}
 }
 
 func (n *graphNodeMissingProvider) ProviderName() string {
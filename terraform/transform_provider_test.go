@@ -1,9 +1,11 @@
 package terraform
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/dag"
 )
 
@@ -53,6 +55,37 @@ func TestMissingProviderTransformer(t *testing.T) {
 	}
 }
 
+func TestMissingProviderTransformer_orphanOnly(t *testing.T) {
+	g := Graph{Path: RootModulePath}
+	g.Add(&graphNodeOrphanResource{
+		ResourceName: "foo_instance.orphan",
+		ResourceType: "foo_instance",
+	})
+	g.Add(&graphNodeOrphanResource{
+		ResourceName: "bar_instance.orphan",
+		ResourceType: "bar_instance",
+	})
+	g.Add(&GraphNodeConfigResource{
+		Resource: &config.Resource{Name: "live", Type: "bar_instance"},
+	})
+
+	transform := &MissingProviderTransformer{Providers: []string{"foo", "bar"}}
+	if err := transform.Transform(&g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := make(map[string]bool)
+	for _, v := range g.Vertices() {
+		if n, ok := v.(*graphNodeMissingProvider); ok {
+			actual[n.ProviderNameValue] = n.OrphanOnly
+		}
+	}
+	expected := map[string]bool{"foo": true, "bar": false}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
 func TestPruneProviderTransformer(t *testing.T) {
 	mod := testModule(t, "transform-provider-prune")
 